@@ -0,0 +1,194 @@
+package slogxslack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go.innotegrity.dev/slogx"
+)
+
+// maxAdminRequestSize is the maximum size of a request body accepted by the admin API.
+const maxAdminRequestSize = 4096
+
+// AdminState holds the runtime state of a handler as reported by the admin API.
+type AdminState struct {
+	// Level is the name of the current minimum log level written by the handler.
+	Level string `json:"level"`
+
+	// LevelAdjustable indicates whether or not the level can be changed at runtime.
+	LevelAdjustable bool `json:"level_adjustable"`
+
+	// Paused indicates whether or not posting to Slack is currently paused.
+	Paused bool `json:"paused"`
+
+	// Routes holds the channel and mentions records of each range of levels are routed to by default.
+	//
+	// Channels and mentions added to the context using WithChannel() and WithMentions() take precedence.
+	Routes []LevelRoute `json:"routes"`
+
+	// Stats holds the handler's delivery statistics.
+	Stats HandlerStats `json:"stats"`
+
+	// Windows holds the handler's schedule windows.
+	Windows []ScheduleWindow `json:"windows"`
+}
+
+// adminLevelRequest is the body of a request to change the handler's level.
+type adminLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// adminErrorResponse is the body returned by the admin API when a request fails.
+type adminErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// AdminHandler returns an http.Handler which exposes a small JSON API for inspecting and controlling the handler at
+// runtime.
+//
+// The API has the following endpoints, relative to wherever the handler is mounted (use http.StripPrefix() to mount
+// it under a path):
+//
+//   - GET /: returns the handler's AdminState, including its level, routes, schedule windows and statistics
+//   - PUT /level: changes the level to the one named by the "level" field of the JSON body (eg: {"level": "warn"})
+//   - POST /pause: pauses posting to Slack
+//   - POST /resume: resumes posting to Slack
+//   - POST /flush: waits for any pending records to be delivered, or for the request to be cancelled
+//   - POST /windows: adds or replaces the ScheduleWindow in the JSON body
+//   - DELETE /windows/NAME: removes the schedule window with the given name
+//
+// Every successful request returns the handler's AdminState. Failed requests return a JSON object with an "error"
+// field. The API applies to the handler and every handler created from it or its parent using WithAttrs() or
+// WithGroup(), and is safe for concurrent use while records are being delivered.
+//
+// The API does not perform any authentication, so it should only be exposed on a trusted interface or wrapped in an
+// authenticating handler.
+func (h *slackHandler) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimSuffix(r.URL.Path, "/") {
+		case "":
+			if !allowMethod(w, r, http.MethodGet, http.MethodHead) {
+				return
+			}
+		case "/level":
+			if !allowMethod(w, r, http.MethodPut, http.MethodPost) {
+				return
+			}
+			if err := h.adminSetLevel(r); err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, ErrLevelNotAdjustable) {
+					status = http.StatusConflict
+				}
+				writeAdminResponse(w, status, adminErrorResponse{Error: err.Error()})
+				return
+			}
+		case "/pause":
+			if !allowMethod(w, r, http.MethodPost) {
+				return
+			}
+			h.Pause()
+		case "/resume":
+			if !allowMethod(w, r, http.MethodPost) {
+				return
+			}
+			h.Resume()
+		case "/flush":
+			if !allowMethod(w, r, http.MethodPost) {
+				return
+			}
+			if err := h.Flush(r.Context()); err != nil {
+				writeAdminResponse(w, http.StatusInternalServerError, adminErrorResponse{Error: err.Error()})
+				return
+			}
+		case "/windows":
+			if !allowMethod(w, r, http.MethodPost) {
+				return
+			}
+			if err := h.adminSetScheduleWindow(r); err != nil {
+				writeAdminResponse(w, http.StatusBadRequest, adminErrorResponse{Error: err.Error()})
+				return
+			}
+		default:
+			if name, ok := strings.CutPrefix(r.URL.Path, "/windows/"); ok && name != "" {
+				if !allowMethod(w, r, http.MethodDelete) {
+					return
+				}
+				if !h.RemoveScheduleWindow(name) {
+					writeAdminResponse(w, http.StatusNotFound, adminErrorResponse{Error: "schedule window not found"})
+					return
+				}
+				break
+			}
+			writeAdminResponse(w, http.StatusNotFound, adminErrorResponse{Error: "not found"})
+			return
+		}
+		writeAdminResponse(w, http.StatusOK, h.adminState())
+	})
+}
+
+// adminSetLevel changes the handler's level to the one named in the request's body.
+func (h *slackHandler) adminSetLevel(r *http.Request) error {
+	var req adminLevelRequest
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAdminRequestSize))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return errors.New("request body must be a JSON object with a 'level' field")
+	}
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		return err
+	}
+	return h.SetLevel(level)
+}
+
+// adminSetScheduleWindow adds or replaces the schedule window in the request's body.
+func (h *slackHandler) adminSetScheduleWindow(r *http.Request) error {
+	var window ScheduleWindow
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAdminRequestSize))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, &window); err != nil {
+		return fmt.Errorf("request body must be a JSON schedule window: %w", err)
+	}
+	return h.SetScheduleWindow(window)
+}
+
+// adminState returns the handler's current runtime state.
+func (h *slackHandler) adminState() AdminState {
+	_, adjustable := h.options.Level.(*slog.LevelVar)
+	return AdminState{
+		Level:           slogx.Level(h.Level()).String(),
+		LevelAdjustable: adjustable,
+		Paused:          h.Paused(),
+		Routes:          h.levelRoutes(),
+		Stats:           h.Stats(),
+		Windows:         h.ScheduleWindows(),
+	}
+}
+
+// allowMethod writes an error response and returns false if the request's method is not one of the given methods.
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeAdminResponse(w, http.StatusMethodNotAllowed, adminErrorResponse{Error: "method not allowed"})
+	return false
+}
+
+// writeAdminResponse writes the given value to the response as JSON.
+func writeAdminResponse(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,116 @@
+package slogxslack_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestAdminHandler(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	admin := httptest.NewServer(http.StripPrefix("/admin", handler.AdminHandler()))
+	defer admin.Close()
+
+	call := func(method, path, body string, expectedStatus int) slogxslack.AdminState {
+		t.Helper()
+		req, err := http.NewRequest(method, admin.URL+"/admin"+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %s", method, path, err.Error())
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != expectedStatus {
+			t.Fatalf("%s %s: expected status %d, got %d", method, path, expectedStatus, resp.StatusCode)
+		}
+		var state slogxslack.AdminState
+		_ = json.NewDecoder(resp.Body).Decode(&state)
+		return state
+	}
+
+	state := call(http.MethodPut, "/level", `{"level": "warn"}`, http.StatusOK)
+	if state.Level != "WARN" || handler.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("expected the level to be changed to WARN, got %s", state.Level)
+	}
+	call(http.MethodPut, "/level", `{"level": "verbose"}`, http.StatusBadRequest)
+	call(http.MethodGet, "/pause", "", http.StatusMethodNotAllowed)
+
+	call(http.MethodPost, "/pause", "", http.StatusOK)
+	logger := slog.New(handler)
+	logger.Error("dropped while paused")
+	call(http.MethodPost, "/resume", "", http.StatusOK)
+	logger.Error("delivered after resume")
+	state = call(http.MethodPost, "/flush", "", http.StatusOK)
+
+	server.WaitForMessages(t, 1, time.Second)
+	if state.Paused || state.Stats.Delivered != 1 || state.Stats.Dropped != 1 || state.Stats.Pending != 0 {
+		t.Errorf("unexpected state: %+v", state)
+	}
+}
+
+func TestSetLevelRequiresLevelVar(t *testing.T) {
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Level:      slog.LevelWarn,
+		WebhookURL: slogxslack.NewSecret("http://localhost"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	if err := handler.SetLevel(slog.LevelDebug); !errors.Is(err, slogxslack.ErrLevelNotAdjustable) {
+		t.Errorf("expected ErrLevelNotAdjustable, got %v", err)
+	}
+}
+
+func TestAdminHandlerRoutes(t *testing.T) {
+	formatterOpts := slogxslack.DefaultSlackMessageFormatterOptions()
+	formatterOpts.Levels = slogxslack.DefaultLevelRegistry().
+		DefineRange(slog.LevelError, slog.LevelError+3, slogxslack.LevelStyle{Channel: "#alerts", Label: "error"}).
+		Define(slog.LevelError+4, slogxslack.LevelStyle{Label: "critical", Mentions: []string{"@oncall"}})
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		RecordFormatter: slogxslack.NewSlackMessageFormatter(formatterOpts),
+		WebhookURL:      slogxslack.NewSecret("http://localhost"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var state slogxslack.AdminState
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatalf("failed to decode state: %s", err.Error())
+	}
+	expected := []slogxslack.LevelRoute{
+		{Channel: "#alerts", MaxLevel: "ERROR+3", MinLevel: "ERROR"},
+		{MaxLevel: "FATAL", Mentions: []string{"@oncall"}, MinLevel: "FATAL"},
+	}
+	if len(state.Routes) != len(expected) {
+		t.Fatalf("expected %d routes, got %+v", len(expected), state.Routes)
+	}
+	for i, e := range expected {
+		r := state.Routes[i]
+		if r.Channel != e.Channel || r.MinLevel != e.MinLevel || r.MaxLevel != e.MaxLevel ||
+			strings.Join(r.Mentions, ",") != strings.Join(e.Mentions, ",") {
+			t.Errorf("expected route %d to be %+v, got %+v", i, e, r)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package slogxslack
+
+import (
+	"log/slog"
+
+	"go.innotegrity.dev/generic"
+)
+
+// groupOrAttrs holds either a group name or a list of attributes added to a handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// withGroupOrAttrs returns a copy of the given list with the new group or attributes appended to it.
+//
+// A copy is always returned so that handlers derived from the same parent never share the underlying array.
+func withGroupOrAttrs(goas []groupOrAttrs, goa groupOrAttrs) []groupOrAttrs {
+	newGoas := make([]groupOrAttrs, len(goas), len(goas)+1)
+	copy(newGoas, goas)
+	if goa.attrs != nil {
+		goa.attrs = append([]slog.Attr{}, goa.attrs...)
+	}
+	return append(newGoas, goa)
+}
+
+// buildAttrs combines the groups and attributes added to a handler with the attributes from a record.
+//
+// The record's attributes are qualified by every group added to the handler, and any attributes added to the handler
+// are qualified by the groups which were added before them. Any extra attributes are added to the top level, before
+// the handler's attributes, so that they are replaced by any duplicates. The result is cleaned using
+// consolidateAttrs().
+func buildAttrs(goas []groupOrAttrs, r slog.Record, extra ...slog.Attr) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for i := len(goas) - 1; i >= 0; i-- {
+		if goas[i].group != "" {
+			attrs = consolidateAttrs(attrs)
+			if len(attrs) == 0 {
+				continue
+			}
+			attrs = []slog.Attr{slog.Group(goas[i].group, generic.AnySlice(attrs)...)}
+		} else {
+			attrs = append(append([]slog.Attr{}, goas[i].attrs...), attrs...)
+		}
+	}
+	return consolidateAttrs(append(append([]slog.Attr{}, extra...), attrs...))
+}
+
+// consolidateAttrs resolves and cleans up the given attributes, following the rules used by log/slog handlers.
+//
+// Empty attributes and groups without any attributes are removed and groups with an empty key are inlined. If an
+// attribute is duplicated within the same group, the last value found is used for the attribute's value. Duplicated
+// groups are merged together.
+func consolidateAttrs(attrs []slog.Attr) []slog.Attr {
+	consolidated := make([]slog.Attr, 0, len(attrs))
+	indexes := map[string]int{}
+	var add func(a slog.Attr)
+	add = func(a slog.Attr) {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			return
+		}
+		if a.Value.Kind() == slog.KindGroup {
+			group := consolidateAttrs(a.Value.Group())
+			if len(group) == 0 {
+				return
+			}
+			if a.Key == "" {
+				for _, ga := range group {
+					add(ga)
+				}
+				return
+			}
+			if i, ok := indexes[a.Key]; ok && consolidated[i].Value.Kind() == slog.KindGroup {
+				group = consolidateAttrs(append(append([]slog.Attr{}, consolidated[i].Value.Group()...), group...))
+			}
+			a.Value = slog.GroupValue(group...)
+		}
+		if i, ok := indexes[a.Key]; ok {
+			consolidated[i] = a
+			return
+		}
+		indexes[a.Key] = len(consolidated)
+		consolidated = append(consolidated, a)
+	}
+	for _, a := range attrs {
+		add(a)
+	}
+	return consolidated
+}
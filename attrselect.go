@@ -0,0 +1,92 @@
+package slogxslack
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// attrSelected determines whether or not the attribute with the given full, dotted key is displayed, based on the
+// AllowAttrs and IgnoreAttrs options.
+func (f slackMessageFormatter) attrSelected(key string) bool {
+	if f.allowedAttrPatterns != nil {
+		allowed := false
+		for _, p := range f.allowedAttrPatterns {
+			if p.MatchString(key) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	for _, p := range f.ignoredAttrPatterns {
+		if p.MatchString(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// includeAttrs determines whether or not the attributes of a record with the given level are displayed, based on the
+// IncludeAttrs and IncludeAttrsLevel options.
+func (f slackMessageFormatter) includeAttrs(level slog.Leveler) bool {
+	if !f.options.IncludeAttrs {
+		return false
+	}
+	return f.options.IncludeAttrsLevel == nil || level.Level() >= f.options.IncludeAttrsLevel.Level()
+}
+
+// orderAttrs moves the flattened attributes whose keys are listed in the AttrOrder option to the front, in the order
+// they are listed, leaving the remaining attributes in their original order.
+func (f slackMessageFormatter) orderAttrs(attrs []slog.Attr) []slog.Attr {
+	if len(f.options.AttrOrder) == 0 {
+		return attrs
+	}
+	priorities := map[string]int{}
+	for i, key := range f.options.AttrOrder {
+		if _, ok := priorities[key]; !ok {
+			priorities[key] = i
+		}
+	}
+	rank := func(a slog.Attr) int {
+		if p, ok := priorities[a.Key]; ok {
+			return p
+		}
+		return len(f.options.AttrOrder)
+	}
+	ordered := append([]slog.Attr{}, attrs...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+	return ordered
+}
+
+// takeAttrs removes the first of the flattened attributes with each of the given keys, returning them in the order the
+// keys are listed along with the remaining attributes.
+func takeAttrs(keys []string, attrs []slog.Attr) ([]slog.Attr, []slog.Attr) {
+	if len(keys) == 0 {
+		return nil, attrs
+	}
+	wanted := map[string]bool{}
+	for _, key := range keys {
+		wanted[key] = true
+	}
+	found := map[string]slog.Attr{}
+	rest := []slog.Attr{}
+	for _, attr := range attrs {
+		if _, ok := found[attr.Key]; !ok && wanted[attr.Key] {
+			found[attr.Key] = attr
+			continue
+		}
+		rest = append(rest, attr)
+	}
+	taken := []slog.Attr{}
+	for _, key := range keys {
+		if attr, ok := found[key]; ok {
+			taken = append(taken, attr)
+			delete(found, key)
+		}
+	}
+	return taken, rest
+}
@@ -0,0 +1,84 @@
+package slogxslack_test
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+func TestAttrSelection(t *testing.T) {
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.AllowAttrs = []string{`^(service|user_id|error|http\.)`}
+	opts.AttrOrder = []string{"service", "user_id", "error"}
+	opts.IgnoreAttrs = []string{`^http\.headers\.`}
+	f := slogxslack.NewSlackMessageFormatter(opts)
+
+	attrs := []slog.Attr{
+		slog.String("error", "timeout"),
+		slog.Group("http", slog.Int("status", 504), slog.Group("headers", slog.String("cookie", "secret"))),
+		slog.String("internal", "hidden"),
+		slog.String("service", "api"),
+		slog.String("user_id", "u-1"),
+	}
+	message, err := f.FormatRecord(context.Background(), time.Now(), slogx.LevelError, 0, "failed", attrs)
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	expected := []string{"*service*: `api`", "*user_id*: `u-1`", "*error*: `timeout`", "*http.status*: `504`"}
+	if texts := attrTexts(message); !reflect.DeepEqual(texts, expected) {
+		t.Errorf("expected attributes %q, got %q", expected, texts)
+	}
+}
+
+func TestAttrPromotion(t *testing.T) {
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.HeaderAttrs = []string{"service"}
+	opts.IncludeAttrsLevel = slogx.LevelWarn
+	opts.KeyAttrs = []string{"env"}
+	opts.Layout = slogxslack.MessageLayoutHeader
+	f := slogxslack.NewSlackMessageFormatter(opts)
+
+	attrs := []slog.Attr{slog.String("env", "prod"), slog.String("host", "web-1"), slog.String("service", "api")}
+	message, err := f.FormatRecord(context.Background(), time.Now(), slogx.LevelInfo, 0, "started", attrs)
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	header := message.Blocks.BlockSet[0].(*slack.HeaderBlock)
+	if !strings.HasSuffix(header.Text.Text, "started  |  service: api") {
+		t.Errorf("expected the service to be promoted to the header, got %q", header.Text.Text)
+	}
+	if texts := attrTexts(message); !reflect.DeepEqual(texts, []string{"*env*: `prod`"}) {
+		t.Errorf("expected only the summary below warn, got %q", texts)
+	}
+
+	if message, err = f.FormatRecord(context.Background(), time.Now(), slogx.LevelWarn, 0, "slow", attrs); err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	if texts := attrTexts(message); !reflect.DeepEqual(texts, []string{"*env*: `prod`", "*host*: `web-1`"}) {
+		t.Errorf("expected the remaining attributes at warn, got %q", texts)
+	}
+}
+
+// attrTexts returns the text of the context blocks of the message which display attributes.
+func attrTexts(message *slack.WebhookMessage) []string {
+	texts := []string{}
+	for _, b := range message.Blocks.BlockSet {
+		c, ok := b.(*slack.ContextBlock)
+		if !ok {
+			continue
+		}
+		for _, e := range c.ContextElements.Elements {
+			if o, ok := e.(slack.TextBlockObject); ok && strings.HasPrefix(o.Text, "*") {
+				texts = append(texts, o.Text)
+			}
+		}
+	}
+	return texts
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+// recordHandler is the part of slog.Handler used to send parsed records.
+type recordHandler interface {
+	// Enabled should determine whether or not records with the given level are handled.
+	Enabled(context.Context, slog.Level) bool
+
+	// Handle should handle the given record.
+	Handle(context.Context, slog.Record) error
+}
+
+// dryRunHandler prints the message each record would be posted as instead of posting it.
+type dryRunHandler struct {
+	formatter slogxslack.SlackMessageFormatter
+	level     slog.Level
+	out       io.Writer
+}
+
+// newDryRunHandler creates a new handler using the formatter and level from the configuration.
+//
+// Unlike the Slack handler, a webhook URL is not required.
+func newDryRunHandler(c slogxslack.Config, out io.Writer) (*dryRunHandler, error) {
+	level, err := slogxslack.ParseLevel(c.Level)
+	if err != nil {
+		return nil, &slogxslack.ConfigError{Key: "level", Err: err}
+	}
+	formatterOpts, err := c.Formatter.FormatterOptions()
+	if err != nil {
+		return nil, err
+	}
+	return &dryRunHandler{
+		formatter: slogxslack.NewSlackMessageFormatter(formatterOpts),
+		level:     level,
+		out:       out,
+	}, nil
+}
+
+// Enabled determines whether or not the given level is enabled in this handler.
+func (h *dryRunHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+// Handle formats the record and prints the resulting message as indented JSON.
+func (h *dryRunHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	message, err := h.formatter.FormatRecord(ctx, r.Time, slogx.Level(r.Level), r.PC, r.Message, attrs)
+	if err != nil || message == nil {
+		return err
+	}
+	data, err := json.MarshalIndent(message, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(h.out, "%s\n", data)
+	return err
+}
+
+// filterHandler only passes records on to the next handler if they match a filter and are within the rate limit.
+type filterHandler struct {
+	dropped     int
+	match       *regexp.Regexp
+	mu          sync.Mutex
+	next        recordHandler
+	rateLimit   int
+	sent        int
+	stderr      io.Writer
+	windowStart time.Time
+}
+
+// newFilterHandler creates a new handler.
+//
+// If match is nil, every message matches. If rateLimit is zero, records are not rate limited.
+func newFilterHandler(next recordHandler, match *regexp.Regexp, rateLimit int, stderr io.Writer) *filterHandler {
+	return &filterHandler{
+		match:     match,
+		next:      next,
+		rateLimit: rateLimit,
+		stderr:    stderr,
+	}
+}
+
+// Enabled determines whether or not the given level is enabled in the next handler.
+func (h *filterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record on to the next handler if its message matches and it is within the rate limit.
+//
+// At most rateLimit records are passed on each minute. The number of records dropped because of the rate limit is
+// reported once the minute is over.
+func (h *filterHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.match != nil && !h.match.MatchString(r.Message) {
+		return nil
+	}
+	if h.rateLimit > 0 && !h.allow(time.Now()) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// allow determines whether or not another record can be passed on at the given time.
+func (h *filterHandler) allow(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if now.Sub(h.windowStart) >= time.Minute {
+		h.report()
+		h.sent = 0
+		h.windowStart = now
+	}
+	if h.sent >= h.rateLimit {
+		h.dropped++
+		return false
+	}
+	h.sent++
+	return true
+}
+
+// close reports any records which have been dropped because of the rate limit.
+func (h *filterHandler) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.report()
+}
+
+// report writes the number of records dropped because of the rate limit, if any, and resets it.
+func (h *filterHandler) report() {
+	if h.dropped > 0 {
+		fmt.Fprintf(h.stderr, "slogx-slack: rate limit of %d records per minute exceeded, dropped %d records\n",
+			h.rateLimit, h.dropped)
+		h.dropped = 0
+	}
+}
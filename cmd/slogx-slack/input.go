@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.innotegrity.dev/slogx-slack/logparse"
+)
+
+// maxLineSize is the maximum size of a log line.
+const maxLineSize = 1024 * 1024
+
+// processLines parses each line read from the input and sends the resulting record to the handler.
+//
+// Lines which cannot be parsed are skipped with a warning. Processing stops at the end of the input or when the
+// context is canceled.
+func processLines(ctx context.Context, input io.Reader, parse logparse.ParseFn, h recordHandler,
+	stderr io.Writer) error {
+
+	// read lines in the background so that a blocking read does not prevent stopping when the context is canceled
+	lines := make(chan []byte)
+	done := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		for scanner.Scan() {
+			line := append([]byte{}, scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		done <- scanner.Err()
+	}()
+
+	lineNumber := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-done:
+			return err
+		case line := <-lines:
+			lineNumber++
+			r, err := parse(line)
+			if errors.Is(err, logparse.ErrEmptyLine) {
+				continue
+			} else if err != nil {
+				fmt.Fprintf(stderr, "slogx-slack: skipping line %d: %s\n", lineNumber, err.Error())
+				continue
+			}
+			if !h.Enabled(ctx, r.Level) {
+				continue
+			}
+			if err := h.Handle(ctx, r); err != nil {
+				fmt.Fprintf(stderr, "slogx-slack: failed to handle line %d: %s\n", lineNumber, err.Error())
+			}
+		}
+	}
+}
+
+// followReader reads a file, waiting for more data to be appended to it at the end of the file like tail -f.
+type followReader struct {
+	ctx      context.Context
+	file     *os.File
+	interval time.Duration
+}
+
+// newFollowReader creates a new reader which polls the file for new data at the given interval.
+func newFollowReader(ctx context.Context, file *os.File, interval time.Duration) *followReader {
+	if interval <= 0 {
+		interval = 250 * time.Millisecond
+	}
+	return &followReader{
+		ctx:      ctx,
+		file:     file,
+		interval: interval,
+	}
+}
+
+// Read reads data from the file, waiting until data is available.
+//
+// If the file is truncated, reading starts again from the beginning of the file. io.EOF is only returned once the
+// context is canceled.
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.file.Read(p)
+		if n > 0 || (err != nil && err != io.EOF) {
+			return n, err
+		}
+
+		// start again if the file was truncated
+		offset, err := r.file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		if info, err := r.file.Stat(); err == nil && info.Size() < offset {
+			if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(r.interval):
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Command slogx-slack reads log lines from stdin or a file and posts them to Slack.
+//
+// This allows Slack alerts to be added to legacy processes and cron jobs without changing their code:
+//
+//	my-cron-job 2>&1 | slogx-slack -webhook-url-file /run/secrets/slack -level warn
+//	slogx-slack -file /var/log/app.json -follow -config slack.yaml
+//	legacy-app | slogx-slack -format logfmt -level error
+//
+// Lines may be written by slog.JSONHandler (-format json, the default), slog.TextHandler (-format text) or any logfmt
+// library (-format logfmt).
+//
+// Settings are loaded from the configuration file given by -config, then from the environment (see
+// slogxslack.LoadConfig) and finally from any flags which are set. Run with -help for the full list of flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/logparse"
+)
+
+// stringsFlag is a flag which may be repeated to build a list of values.
+type stringsFlag []string
+
+// String returns the values of the flag.
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds a value to the flag.
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// options holds the settings which do not map to the configuration.
+type options struct {
+	configPath   string
+	dryRun       bool
+	envPrefix    string
+	file         string
+	follow       bool
+	format       string
+	match        *regexp.Regexp
+	pollInterval time.Duration
+	rateLimit    int
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the given arguments and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c, opts, err := parseFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if err != nil {
+		fmt.Fprintf(stderr, "slogx-slack: %s\n", err.Error())
+		return 2
+	}
+
+	// create the handler records are sent to
+	var h recordHandler
+	var shutdown func(bool) error
+	if opts.dryRun {
+		if h, err = newDryRunHandler(c, stdout); err != nil {
+			fmt.Fprintf(stderr, "slogx-slack: invalid configuration: %s\n", err.Error())
+			return 2
+		}
+	} else {
+		handler, err := slogxslack.NewSlackHandlerFromConfig(c)
+		if err != nil {
+			fmt.Fprintf(stderr, "slogx-slack: invalid configuration: %s\n", err.Error())
+			return 2
+		}
+		h, shutdown = handler, handler.Shutdown
+	}
+	filter := newFilterHandler(h, opts.match, opts.rateLimit, stderr)
+
+	// open the input
+	input := stdin
+	if opts.file != "" {
+		f, err := os.Open(opts.file)
+		if err != nil {
+			fmt.Fprintf(stderr, "slogx-slack: %s\n", err.Error())
+			return 1
+		}
+		defer f.Close()
+		input = f
+		if opts.follow {
+			if _, err := f.Seek(0, io.SeekEnd); err != nil {
+				fmt.Fprintf(stderr, "slogx-slack: %s\n", err.Error())
+				return 1
+			}
+			input = newFollowReader(ctx, f, opts.pollInterval)
+		}
+	}
+
+	// process every line and then wait for the handler to finish posting
+	code := 0
+	parse, _ := parser(opts.format)
+	if err := processLines(ctx, input, parse, filter, stderr); err != nil {
+		fmt.Fprintf(stderr, "slogx-slack: failed to read input: %s\n", err.Error())
+		code = 1
+	}
+	filter.close()
+	if shutdown != nil {
+		// wait for at most the configured shutdown timeout, even if the command was interrupted
+		if err := shutdown(true); err != nil {
+			fmt.Fprintf(stderr, "slogx-slack: failed to post records: %s\n", err.Error())
+			code = 1
+		}
+	}
+	return code
+}
+
+// parser returns the function which parses log lines in the given format.
+func parser(format string) (logparse.ParseFn, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return logparse.ParseJSON, nil
+	case "logfmt":
+		return logparse.ParseLogfmt, nil
+	case "text":
+		return logparse.ParseText, nil
+	}
+	return nil, fmt.Errorf("unknown -format '%s'", format)
+}
+
+// parseFlags parses the command-line flags and loads the configuration.
+func parseFlags(args []string, stderr io.Writer) (slogxslack.Config, options, error) {
+	var opts options
+	var match string
+	var allowAttrs, ignoreAttrs, keyAttrs stringsFlag
+	fs := flag.NewFlagSet("slogx-slack", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	// general options
+	fs.StringVar(&opts.configPath, "config", "", "path to a YAML or JSON configuration `file`")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print the Block Kit JSON of each message instead of posting it")
+	fs.StringVar(&opts.envPrefix, "env-prefix", slogxslack.DefaultConfigEnvPrefix,
+		"`prefix` of the environment variables to load settings from")
+	fs.StringVar(&opts.file, "file", "", "read log lines from `path` instead of stdin")
+	fs.BoolVar(&opts.follow, "follow", false, "keep reading lines appended to -file, like tail -f")
+	fs.StringVar(&opts.format, "format", "json", "`format` of the log lines (json, logfmt or text)")
+	fs.StringVar(&match, "match", "", "only post records whose message matches the regular `expression`")
+	fs.DurationVar(&opts.pollInterval, "poll-interval", 250*time.Millisecond,
+		"how often to check -file for new lines when following it")
+	fs.IntVar(&opts.rateLimit, "rate-limit", 0, "maximum number of records to post per minute (0 for no limit)")
+
+	// handler options
+	async := fs.Bool("async", false, "post records asynchronously")
+	compatibility := fs.String("compatibility", "", "type of Slack-compatible `webhook` "+
+		"(slack, mattermost, rocketchat or discord)")
+	httpTimeout := fs.Duration("http-timeout", 0, "timeout for posting each record")
+	level := fs.String("level", "", "minimum `level` of records to post (default info)")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 0, "how long to wait for pending records when exiting")
+	webhookURL := fs.String("webhook-url", "", "Slack webhook `URL` (prefer -webhook-url-file or the environment)")
+	webhookURLFile := fs.String("webhook-url-file", "", "`path` to a file containing the Slack webhook URL")
+
+	// formatter options
+	appIconURL := fs.String("app-icon-url", "", "`URL` of the application icon shown in messages")
+	appName := fs.String("app-name", "", "application `name` shown in messages")
+	fs.Var(&allowAttrs, "allow-attr", "regular `expression` of the only attributes to include (may be repeated)")
+	attrFormatter := fs.String("attr-formatter", "", "registered attribute formatter `name`")
+	enrich := fs.Bool("enrich", false, "show the host, process, version and Kubernetes pod at the bottom of messages")
+	fs.Var(&ignoreAttrs, "ignore-attr", "regular `expression` of attributes to leave out (may be repeated)")
+	includeAttrs := fs.Bool("include-attrs", true, "include attributes in messages")
+	includeAttrsLevel := fs.String("include-attrs-level", "", "minimum `level` of records to include attributes for")
+	includeSource := fs.Bool("include-source", false, "include the source attribute in messages")
+	fs.Var(&keyAttrs, "key-attr", "`key` of an attribute to summarize beneath the header (may be repeated)")
+	layout := fs.String("layout", "", "message `layout` (default or header)")
+	levelFormatter := fs.String("level-formatter", "", "registered level formatter `name`")
+	sortAttrs := fs.Bool("sort-attrs", true, "sort attributes by key")
+	sourceFormatter := fs.String("source-formatter", "", "registered source formatter `name`")
+	timeDateToken := fs.String("time-date-token", "", "Slack date token `format` for showing the time in each "+
+		"reader's timezone (eg: "+slogxslack.DefaultTimeDateToken+")")
+	timeFormatter := fs.String("time-formatter", "", "registered time formatter `name`")
+
+	if err := fs.Parse(args); err != nil {
+		return slogxslack.Config{}, opts, err
+	}
+	if fs.NArg() > 0 {
+		return slogxslack.Config{}, opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if opts.follow && opts.file == "" {
+		return slogxslack.Config{}, opts, errors.New("-follow requires -file")
+	}
+	if _, err := parser(opts.format); err != nil {
+		return slogxslack.Config{}, opts, err
+	}
+	if match != "" {
+		var err error
+		if opts.match, err = regexp.Compile(match); err != nil {
+			return slogxslack.Config{}, opts, fmt.Errorf("invalid -match expression: %w", err)
+		}
+	}
+
+	// load the configuration and override it with any flags which were set
+	c := slogxslack.DefaultConfig()
+	if opts.configPath != "" {
+		if err := c.LoadFile(opts.configPath); err != nil {
+			return c, opts, err
+		}
+	}
+	if err := c.LoadEnv(opts.envPrefix); err != nil {
+		return c, opts, err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "allow-attr":
+			c.Formatter.AllowAttrs = allowAttrs
+		case "app-icon-url":
+			c.Formatter.ApplicationIconURL = *appIconURL
+		case "app-name":
+			c.Formatter.ApplicationName = *appName
+		case "async":
+			c.EnableAsync = *async
+		case "attr-formatter":
+			c.Formatter.AttrFormatter = *attrFormatter
+		case "compatibility":
+			c.Compatibility = *compatibility
+		case "enrich":
+			c.Formatter.Enrich = *enrich
+		case "http-timeout":
+			c.HTTPTimeout = slogxslack.ConfigDuration(*httpTimeout)
+		case "ignore-attr":
+			c.Formatter.IgnoreAttrs = ignoreAttrs
+		case "include-attrs":
+			c.Formatter.IncludeAttrs = *includeAttrs
+		case "include-attrs-level":
+			c.Formatter.IncludeAttrsLevel = *includeAttrsLevel
+		case "include-source":
+			c.Formatter.IncludeSource = *includeSource
+		case "key-attr":
+			c.Formatter.KeyAttrs = keyAttrs
+		case "layout":
+			c.Formatter.Layout = *layout
+		case "level":
+			c.Level = *level
+		case "level-formatter":
+			c.Formatter.LevelFormatter = *levelFormatter
+		case "shutdown-timeout":
+			c.ShutdownTimeout = slogxslack.ConfigDuration(*shutdownTimeout)
+		case "sort-attrs":
+			c.Formatter.SortAttrs = *sortAttrs
+		case "source-formatter":
+			c.Formatter.SourceFormatter = *sourceFormatter
+		case "time-date-token":
+			c.Formatter.TimeDateToken = *timeDateToken
+		case "time-formatter":
+			c.Formatter.TimeFormatter = *timeFormatter
+		case "webhook-url":
+			c.WebhookURL = slogxslack.NewSecret(*webhookURL)
+		case "webhook-url-file":
+			c.WebhookURLFile = *webhookURLFile
+		}
+	})
+	return c, opts, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+const testInput = `{"time":"2024-01-02T03:04:05Z","level":"ERROR","msg":"backup failed","job":{"name":"nightly"}}
+not a log line
+{"time":"2024-01-02T03:04:06Z","level":"DEBUG","msg":"debugging"}
+{"time":"2024-01-02T03:04:07Z","level":"WARN","msg":"backup slow"}
+{"time":"2024-01-02T03:04:08Z","level":"ERROR","msg":"backup failed again"}
+`
+
+func TestRunPostsRecords(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-webhook-url", server.WebhookURL(), "-level", "warn", "-match", "failed", "-rate-limit", "1"}
+	if code := run(context.Background(), args, strings.NewReader(testInput), &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	slacktest.AssertContainsText(t, messages[0], "backup failed")
+	slacktest.AssertContainsText(t, messages[0], "nightly")
+	if !strings.Contains(stderr.String(), "skipping line 2") {
+		t.Errorf("expected a warning for the invalid line, got %q", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "dropped 1 records") {
+		t.Errorf("expected the rate limited record to be reported, got %q", stderr.String())
+	}
+}
+
+func TestRunShutdownTimeout(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+	server.Delay(1, time.Minute)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-webhook-url", server.WebhookURL(), "-async", "-shutdown-timeout", "100ms"}
+	input := `{"level":"ERROR","msg":"backup failed"}` + "\n"
+	start := time.Now()
+	if code := run(context.Background(), args, strings.NewReader(input), &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1, got %d: %s", code, stderr.String())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the command to stop waiting after the shutdown timeout, took %s", elapsed)
+	}
+	if !strings.Contains(stderr.String(), "abandoned") {
+		t.Errorf("expected the abandoned record to be reported, got %q", stderr.String())
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"-dry-run", "-app-name", "backup-job", "-level", "error"}
+	if code := run(context.Background(), args, strings.NewReader(testInput), &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	decoder := json.NewDecoder(&stdout)
+	count := 0
+	for decoder.More() {
+		var message map[string]any
+		if err := decoder.Decode(&message); err != nil {
+			t.Fatalf("expected JSON output: %s", err.Error())
+		}
+		if _, ok := message["blocks"]; !ok {
+			t.Errorf("expected the message to contain blocks, got %v", message)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("expected 2 messages, got %d", count)
+	}
+}
+
+func TestRunLogfmt(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	input := "ts=2024-01-02T03:04:05Z lvl=error msg=\"backup failed\" job.name=nightly\n"
+	args := []string{"-dry-run", "-format", "logfmt"}
+	if code := run(context.Background(), args, strings.NewReader(input), &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "backup failed") || !strings.Contains(stdout.String(), "nightly") {
+		t.Errorf("expected the record to be formatted, got %s", stdout.String())
+	}
+}
@@ -0,0 +1,304 @@
+package slogxslack
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// CompatibilityProfile identifies the type of Slack-compatible webhook the handler posts messages to.
+type CompatibilityProfile string
+
+const (
+	// CompatibilityDiscord posts messages to Discord's Slack-compatible webhook endpoint (the webhook URL ending in
+	// "/slack").
+	CompatibilityDiscord CompatibilityProfile = "discord"
+
+	// CompatibilityMattermost posts messages to a Mattermost incoming webhook.
+	CompatibilityMattermost CompatibilityProfile = "mattermost"
+
+	// CompatibilityRocketChat posts messages to a Rocket.Chat incoming webhook.
+	CompatibilityRocketChat CompatibilityProfile = "rocketchat"
+
+	// CompatibilitySlack posts messages to a Slack incoming webhook without any conversion.
+	CompatibilitySlack CompatibilityProfile = "slack"
+)
+
+const (
+	// discordMaxAttachments is the maximum number of attachments (embeds) Discord accepts in a message.
+	discordMaxAttachments = 10
+
+	// discordMaxAttachmentText is the maximum number of characters Discord accepts in an attachment's text (an embed's
+	// description).
+	discordMaxAttachmentText = 4096
+
+	// discordMaxText is the maximum number of characters Discord accepts in a message's text.
+	discordMaxText = 2000
+)
+
+var (
+	// compatBoldRegexp matches Slack bold text.
+	compatBoldRegexp = regexp.MustCompile(`(^|[\s(_~])\*([^*\n]+)\*`)
+
+	// compatDateRegexp matches Slack date tokens, capturing their fallback text.
+	compatDateRegexp = regexp.MustCompile(`<!date\^[^|>]*\|([^>]*)>`)
+
+	// compatLinkRegexp matches Slack links, with or without link text.
+	compatLinkRegexp = regexp.MustCompile(`<((?:https?|mailto):[^|>\s]+)(?:\|([^>]*))?>`)
+
+	// compatStrikeRegexp matches Slack struck through text.
+	compatStrikeRegexp = regexp.MustCompile(`(^|[\s(_*])~([^~\n]+)~`)
+
+	// compatEntityDecoder decodes the HTML entities used to escape text in Slack's mrkdwn.
+	compatEntityDecoder = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+	// compatEscapedChars replaces the formatting characters escaped using zero-width spaces (see EscapeMrkdwn()) with
+	// placeholders from the Unicode private use area, so they are not treated as markup while the text is converted.
+	compatEscapedChars = strings.NewReplacer(
+		zeroWidthSpace+"*"+zeroWidthSpace, "\ue000",
+		zeroWidthSpace+"_"+zeroWidthSpace, "\ue001",
+		zeroWidthSpace+"~"+zeroWidthSpace, "\ue002",
+		zeroWidthSpace+"`"+zeroWidthSpace, "\ue003",
+	)
+
+	// compatCodeChars restores the escaped formatting characters within code, where they are displayed literally.
+	compatCodeChars = strings.NewReplacer("\ue000", "*", "\ue001", "_", "\ue002", "~", "\ue003", "`",
+		zeroWidthSpace, "")
+
+	// compatTextChars restores the escaped formatting characters outside of code, escaping them with a backslash as
+	// the targets use Markdown.
+	compatTextChars = strings.NewReplacer("\ue000", "\\*", "\ue001", "\\_", "\ue002", "\\~", "\ue003", "\\`",
+		zeroWidthSpace, "")
+)
+
+// validate determines whether or not the profile is known.
+func (p CompatibilityProfile) validate() error {
+	switch p {
+	case "", CompatibilitySlack, CompatibilityDiscord, CompatibilityMattermost, CompatibilityRocketChat:
+		return nil
+	}
+	return fmt.Errorf("unknown compatibility profile %q", p)
+}
+
+// convert converts the message, which may use Block Kit, into a message the target of the profile supports.
+//
+// Targets other than Slack do not support Block Kit, so the text of every block is collected into a single
+// attachment, using the given color, with the Slack markup converted into the target's markup. Discord rejects messages
+// which exceed its size limits, so for Discord any attachments beyond its limit are dropped and text which is too long
+// is trimmed.
+func (p CompatibilityProfile) convert(color string, message *slack.WebhookMessage) *slack.WebhookMessage {
+	if p == "" || p == CompatibilitySlack || message == nil {
+		return message
+	}
+
+	converted := &slack.WebhookMessage{
+		Attachments: []slack.Attachment{},
+		Channel:     message.Channel,
+		IconEmoji:   message.IconEmoji,
+		IconURL:     message.IconURL,
+		Username:    message.Username,
+	}
+	if message.Blocks != nil && len(message.Blocks.BlockSet) > 0 {
+		converted.Attachments = append(converted.Attachments, slack.Attachment{
+			Color:      color,
+			Fallback:   p.markup(message.Text),
+			MarkdownIn: []string{"pretext", "text", "fields"},
+			Text:       p.markup(compatBlocksText(message.Blocks.BlockSet)),
+		})
+	} else if message.Text != "" {
+		converted.Text = p.markup(message.Text)
+	}
+	for _, a := range message.Attachments {
+		text := a.Text
+		if len(a.Blocks.BlockSet) > 0 {
+			text = strings.TrimSpace(strings.Join([]string{text, compatBlocksText(a.Blocks.BlockSet)}, "\n"))
+		}
+		fields := make([]slack.AttachmentField, 0, len(a.Fields))
+		for _, f := range a.Fields {
+			fields = append(fields, slack.AttachmentField{
+				Short: f.Short,
+				Title: p.markup(f.Title),
+				Value: p.markup(f.Value),
+			})
+		}
+		converted.Attachments = append(converted.Attachments, slack.Attachment{
+			AuthorName: a.AuthorName,
+			Color:      a.Color,
+			Fallback:   p.markup(a.Fallback),
+			Fields:     fields,
+			Footer:     a.Footer,
+			MarkdownIn: []string{"pretext", "text", "fields"},
+			Pretext:    p.markup(a.Pretext),
+			Text:       p.markup(text),
+			Title:      a.Title,
+			TitleLink:  a.TitleLink,
+		})
+	}
+	if len(converted.Attachments) > 0 && converted.Attachments[0].Fallback == "" {
+		converted.Attachments[0].Fallback = p.markup(message.Text)
+	}
+	if len(converted.Attachments) == 0 {
+		converted.Attachments = nil
+	}
+	if p == CompatibilityDiscord {
+		converted.Text = trimText(converted.Text, discordMaxText)
+		if len(converted.Attachments) > discordMaxAttachments {
+			converted.Attachments = converted.Attachments[:discordMaxAttachments]
+		}
+		for i := range converted.Attachments {
+			converted.Attachments[i].Text = trimText(converted.Attachments[i].Text, discordMaxAttachmentText)
+		}
+	}
+	return converted
+}
+
+// markup converts Slack's mrkdwn markup into the markup used by the profile's target.
+//
+// Date tokens are replaced by their fallback text. HTML entities are decoded and formatting characters escaped using
+// zero-width spaces are escaped using a backslash instead. Text within code spans and code blocks is otherwise left
+// untouched.
+func (p CompatibilityProfile) markup(text string) string {
+	if text == "" {
+		return text
+	}
+	parts := strings.Split(compatEscapedChars.Replace(text), "`")
+	for i := range parts {
+		if i%2 == 1 {
+			parts[i] = compatCodeChars.Replace(compatEntityDecoder.Replace(parts[i]))
+			continue
+		}
+		part := compatDateRegexp.ReplaceAllString(parts[i], "$1")
+		part = compatLinkRegexp.ReplaceAllStringFunc(part, func(link string) string {
+			m := compatLinkRegexp.FindStringSubmatch(link)
+			if m[2] == "" {
+				return m[1]
+			}
+			return fmt.Sprintf("[%s](%s)", m[2], m[1])
+		})
+		if p != CompatibilityRocketChat {
+			part = compatBoldRegexp.ReplaceAllString(part, "$1**$2**")
+			part = compatStrikeRegexp.ReplaceAllString(part, "$1~~$2~~")
+		}
+		parts[i] = compatTextChars.Replace(compatEntityDecoder.Replace(part))
+	}
+	return strings.Join(parts, "`")
+}
+
+// compatBlocksText returns the text of the blocks, with a blank line wherever there is a divider.
+func compatBlocksText(blocks []slack.Block) string {
+	lines := []string{}
+	divider := false
+	add := func(text string) {
+		if text == "" {
+			return
+		}
+		if divider && len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		divider = false
+		lines = append(lines, text)
+	}
+	for _, b := range blocks {
+		switch block := b.(type) {
+		case slack.DividerBlock, *slack.DividerBlock:
+			divider = true
+		case slack.HeaderBlock:
+			add(compatTextObject(block.Text, true))
+		case *slack.HeaderBlock:
+			add(compatTextObject(block.Text, true))
+		case slack.SectionBlock:
+			add(compatSectionText(&block))
+		case *slack.SectionBlock:
+			add(compatSectionText(block))
+		case slack.ContextBlock:
+			add(compatContextText(&block))
+		case *slack.ContextBlock:
+			add(compatContextText(block))
+		case slack.RichTextBlock:
+			add(compatRichText(block.Elements))
+		case *slack.RichTextBlock:
+			add(compatRichText(block.Elements))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compatContextText returns the text of the elements of a context block.
+func compatContextText(block *slack.ContextBlock) string {
+	texts := []string{}
+	for _, e := range block.ContextElements.Elements {
+		switch element := e.(type) {
+		case slack.TextBlockObject:
+			texts = append(texts, element.Text)
+		case *slack.TextBlockObject:
+			texts = append(texts, element.Text)
+		}
+	}
+	return strings.Join(texts, "  |  ")
+}
+
+// compatRichText returns the text of rich text elements, using markup for preformatted text, quotes and lists.
+func compatRichText(elements []slack.RichTextElement) string {
+	texts := []string{}
+	for _, e := range elements {
+		switch element := e.(type) {
+		case *slack.RichTextSection:
+			text := compatRichTextSection(element)
+			switch element.Type {
+			case slack.RTEPreformatted:
+				text = "```\n" + text + "\n```"
+			case slack.RTEQuote:
+				text = "> " + strings.ReplaceAll(text, "\n", "\n> ")
+			}
+			texts = append(texts, text)
+		case richTextList:
+			for _, item := range element.Elements {
+				if section, ok := item.(*slack.RichTextSection); ok {
+					texts = append(texts, "• "+compatRichTextSection(section))
+				}
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// compatRichTextSection returns the text of the elements of a rich text section, using Slack link markup for links.
+func compatRichTextSection(section *slack.RichTextSection) string {
+	var text strings.Builder
+	for _, e := range section.Elements {
+		switch element := e.(type) {
+		case *slack.RichTextSectionTextElement:
+			text.WriteString(element.Text)
+		case *slack.RichTextSectionLinkElement:
+			text.WriteString("<" + element.URL + ">")
+		}
+	}
+	return text.String()
+}
+
+// compatSectionText returns the text and fields of a section block.
+func compatSectionText(block *slack.SectionBlock) string {
+	texts := []string{}
+	if text := compatTextObject(block.Text, false); text != "" {
+		texts = append(texts, text)
+	}
+	for _, f := range block.Fields {
+		if text := compatTextObject(f, false); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// compatTextObject returns the text of the object, making it bold if requested.
+func compatTextObject(o *slack.TextBlockObject, bold bool) string {
+	if o == nil || o.Text == "" {
+		return ""
+	}
+	if bold {
+		return "*" + o.Text + "*"
+	}
+	return o.Text
+}
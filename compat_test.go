@@ -0,0 +1,117 @@
+package slogxslack_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestCompatibilityProfiles(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	formatterOpts := slogxslack.DefaultSlackMessageFormatterOptions()
+	formatterOpts.ApplicationName = "compat"
+	formatterOpts.TimeDateToken = slogxslack.DefaultTimeDateToken
+	formatterOpts.LinksFormatter = func(ctx context.Context, level slog.Leveler) ([]slogxslack.SlackMessageLink, error) {
+		return []slogxslack.SlackMessageLink{{Text: "Runbook", URL: "https://example.com/runbook"}}, nil
+	}
+	profiles := []slogxslack.CompatibilityProfile{
+		slogxslack.CompatibilityDiscord,
+		slogxslack.CompatibilityMattermost,
+		slogxslack.CompatibilityRocketChat,
+	}
+	for _, profile := range profiles {
+		t.Run(string(profile), func(t *testing.T) {
+			server.Reset()
+			handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+				Compatibility:   profile,
+				RecordFormatter: slogxslack.NewSlackMessageFormatter(formatterOpts),
+				WebhookURL:      slogxslack.NewSecret(server.CompatWebhookURL(profile)),
+			})
+			if err != nil {
+				t.Fatalf("failed to create handler: %s", err.Error())
+			}
+			err = handler.Handle(context.Background(), newRecord(slog.LevelError, "disk <sda> & `df` *almost* full",
+				slog.String("mount", "value with *markdown* and <brackets>")))
+			if err != nil {
+				t.Fatalf("failed to post message: %s", err.Error())
+			}
+
+			messages := server.Messages()
+			if len(messages) != 1 || messages[0].Endpoint != string(profile) {
+				t.Fatalf("expected 1 message for %s, got %d (%d rejected)", profile, len(messages), server.Rejected())
+			}
+			slacktest.AssertContainsText(t, messages[0], "disk")
+			if messages[0].ContainsText("<!date^") || !messages[0].ContainsText(" UTC") {
+				t.Errorf("expected the date token to be replaced by its fallback text, got %v", messages[0].Texts())
+			}
+			// escaped text is decoded and the formatting characters in it are escaped using Markdown
+			slacktest.AssertContainsText(t, messages[0], "disk <sda> & \\`df\\` \\*almost\\* full")
+			slacktest.AssertContainsText(t, messages[0], "`value with *markdown* and <brackets>`")
+			if profile == slogxslack.CompatibilityRocketChat {
+				slacktest.AssertContainsText(t, messages[0], "[Runbook](https://example.com/runbook)")
+			} else {
+				slacktest.AssertContainsText(t, messages[0], "**mount**")
+			}
+		})
+	}
+}
+
+func TestCompatibilityDiscordLimits(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Compatibility: slogxslack.CompatibilityDiscord,
+		WebhookURL:    slogxslack.NewSecret(server.CompatWebhookURL(slogxslack.CompatibilityDiscord)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	var attrs []slog.Attr
+	for i := 0; i < 5; i++ {
+		attrs = append(attrs, slog.String(fmt.Sprintf("attr%d", i), strings.Repeat("x", 2000)))
+	}
+	if err := handler.Handle(context.Background(), newRecord(slog.LevelError, strings.Repeat("long ", 1000),
+		attrs...)); err != nil {
+		t.Fatalf("failed to post oversized message: %s", err.Error())
+	}
+	if messages := server.Messages(); len(messages) != 1 || server.Rejected() != 0 {
+		t.Fatalf("expected 1 message, got %d (%d rejected)", len(messages), server.Rejected())
+	}
+}
+
+func TestCompatibilityRejectsBlockKit(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		WebhookURL: slogxslack.NewSecret(server.CompatWebhookURL(slogxslack.CompatibilityMattermost)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	if err := handler.Handle(context.Background(), newRecord(slog.LevelInfo, "blocks")); err == nil {
+		t.Error("expected the fake Mattermost webhook to reject a Block Kit message")
+	}
+	if _, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Compatibility: "teams",
+		WebhookURL:    slogxslack.NewSecret(server.WebhookURL()),
+	}); err == nil {
+		t.Error("expected an unknown compatibility profile to be rejected")
+	}
+}
+
+// newRecord creates a new record with the given level, message and attributes.
+func newRecord(level slog.Level, msg string, attrs ...slog.Attr) slog.Record {
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	r.AddAttrs(attrs...)
+	return r
+}
@@ -0,0 +1,905 @@
+package slogxslack
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.innotegrity.dev/slogx-slack/internal/levels"
+	"go.innotegrity.dev/slogx/formatter"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultConfigEnvPrefix is the default prefix for environment variables holding configuration settings.
+	DefaultConfigEnvPrefix = "SLOGX_SLACK_"
+
+	// ConfigFormatJSON indicates the configuration is in JSON format.
+	ConfigFormatJSON ConfigFormat = "json"
+
+	// ConfigFormatYAML indicates the configuration is in YAML format.
+	ConfigFormatYAML ConfigFormat = "yaml"
+)
+
+// ConfigFormat is the format of a configuration file.
+type ConfigFormat string
+
+// ConfigError is returned when a configuration setting is invalid.
+type ConfigError struct {
+	// Err is the reason the setting is invalid.
+	Err error
+
+	// Key is the full, dotted key of the invalid setting (eg: formatter.time_formatter).
+	Key string
+
+	// Source is the name of the environment variable the setting was loaded from, if any.
+	Source string
+}
+
+// Error returns the string version of the error.
+func (e *ConfigError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("invalid configuration setting '%s' (from %s): %s", e.Key, e.Source, e.Err.Error())
+	}
+	return fmt.Sprintf("invalid configuration setting '%s': %s", e.Key, e.Err.Error())
+}
+
+// Unwrap returns the reason the setting is invalid.
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigDuration is a duration which is configured using a string such as "30s" or "1m30s".
+type ConfigDuration time.Duration
+
+// MarshalText returns the duration as a string.
+func (d ConfigDuration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText parses the duration from a string.
+func (d *ConfigDuration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = ConfigDuration(duration)
+	return nil
+}
+
+// Config holds the settings for the Slack handler which can be loaded from the environment or a YAML or JSON file.
+//
+// When loading settings from the environment, each setting is read from an environment variable named after its key
+// in upper case, with any periods replaced by underscores and prefixed with the environment prefix (eg:
+// SLOGX_SLACK_WEBHOOK_URL or SLOGX_SLACK_FORMATTER_TIME_FORMATTER). Lists are comma-separated and maps are
+// comma-separated KEY=VALUE pairs.
+//
+// Records are routed to channels, and mention users, according to their level using the formatter's levels setting
+// (see LevelConfig).
+type Config struct {
+	// Compatibility is the type of Slack-compatible webhook the webhook URL belongs to (slack, mattermost, rocketchat
+	// or discord).
+	//
+	// If empty, slack is used.
+	Compatibility string `json:"compatibility" yaml:"compatibility"`
+
+	// EnableAsync will execute the Handle() function in a separate goroutine.
+	EnableAsync bool `json:"enable_async" yaml:"enable_async"`
+
+	// Formatter holds the settings for the message formatter.
+	Formatter FormatterConfig `json:"formatter" yaml:"formatter"`
+
+	// HTTPTimeout is the maximum amount of time to wait for Slack to respond to a message.
+	//
+	// If zero, there is no timeout.
+	HTTPTimeout ConfigDuration `json:"http_timeout" yaml:"http_timeout"`
+
+	// Level is the name of the minimum log level to write to the handler (eg: info or error+2).
+	Level string `json:"level" yaml:"level"`
+
+	// RateLimit holds the settings for limiting the number of records posted to Slack.
+	RateLimit RateLimitConfig `json:"rate_limit" yaml:"rate_limit"`
+
+	// Retry holds the settings for retrying messages which could not be posted to Slack.
+	Retry RetryConfig `json:"retry" yaml:"retry"`
+
+	// ShutdownTimeout is the maximum amount of time Shutdown() waits for pending records to be delivered.
+	ShutdownTimeout ConfigDuration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+
+	// WebhookURL is the Slack webhook URL to use in order to send the message.
+	//
+	// Either this or WebhookURLFile must be set.
+	WebhookURL Secret `json:"webhook_url" yaml:"webhook_url"`
+
+	// WebhookURLFile is the path to a file containing the Slack webhook URL, such as a mounted Kubernetes secret.
+	//
+	// The file is automatically reloaded when it changes. If this is set, it takes priority over WebhookURL.
+	WebhookURLFile string `json:"webhook_url_file" yaml:"webhook_url_file"`
+
+	// unexported variables
+	fileSecrets *fileSecrets
+}
+
+// FormatterConfig holds the settings for the message formatter which can be loaded from the environment or a YAML
+// or JSON file.
+//
+// Formatting functions are referenced by the name they were registered with (see RegisterAttrFormatter(),
+// RegisterLevelFormatter(), RegisterSourceFormatter() and RegisterTimeFormatter()).
+type FormatterConfig struct {
+	// ApplicationIconURL is a URL to an icon to display next to the application name in the output message.
+	ApplicationIconURL string `json:"application_icon_url" yaml:"application_icon_url"`
+
+	// ApplicationName is the name of the application to display above the message.
+	ApplicationName string `json:"application_name" yaml:"application_name"`
+
+	// AllowAttrs is a list of regular expressions to use for matching the only attributes which should be printed.
+	AllowAttrs []string `json:"allow_attrs" yaml:"allow_attrs"`
+
+	// AttrFormatter is the name of the function to call to format any attribute.
+	AttrFormatter string `json:"attr_formatter" yaml:"attr_formatter"`
+
+	// AttrOrder is a list of the full, dotted keys of the attributes to display first.
+	AttrOrder []string `json:"attr_order" yaml:"attr_order"`
+
+	// AttrRendering determines how attributes nested within groups are displayed ("flat" or "grouped").
+	AttrRendering string `json:"attr_rendering" yaml:"attr_rendering"`
+
+	// Enrich indicates whether or not to display the metadata of the running process, such as the hostname, version
+	// and Kubernetes pod, at the bottom of every message.
+	Enrich bool `json:"enrich" yaml:"enrich"`
+
+	// EnrichmentLabels holds custom static labels, such as the environment or team, to display with the process
+	// metadata.
+	//
+	// Labels are displayed even if Enrich is false.
+	EnrichmentLabels map[string]string `json:"enrichment_labels" yaml:"enrichment_labels"`
+
+	// HeaderAttrs is a list of the full, dotted keys of the attributes whose values are promoted to the header.
+	HeaderAttrs []string `json:"header_attrs" yaml:"header_attrs"`
+
+	// IgnoreAttrs is a list of regular expressions to use for matching attributes which should not be printed.
+	IgnoreAttrs []string `json:"ignore_attrs" yaml:"ignore_attrs"`
+
+	// IncludeAttrs indicates whether or not to include attributes in the Slack message.
+	IncludeAttrs bool `json:"include_attrs" yaml:"include_attrs"`
+
+	// IncludeAttrsLevel is the name or number of the minimum level of the records whose attributes are included.
+	IncludeAttrsLevel string `json:"include_attrs_level" yaml:"include_attrs_level"`
+
+	// IncludeSource indicates whether or not to include source file location information in the Slack mesage.
+	IncludeSource bool `json:"include_source" yaml:"include_source"`
+
+	// KeyAttrs is a list of the full, dotted keys of the attributes to summarize beneath the header.
+	KeyAttrs []string `json:"key_attrs" yaml:"key_attrs"`
+
+	// Layout determines how the blocks of the message are arranged ("default" or "header").
+	Layout string `json:"layout" yaml:"layout"`
+
+	// LevelFormatter is the name of the function to call to format the level.
+	LevelFormatter string `json:"level_formatter" yaml:"level_formatter"`
+
+	// Levels holds the styles of levels to define in addition to, or in place of, the levels of the default level
+	// registry.
+	Levels []LevelConfig `json:"levels" yaml:"levels"`
+
+	// MessageRendering determines how the message is rendered ("escaped", "mrkdwn" or "rich_text").
+	MessageRendering string `json:"message_rendering" yaml:"message_rendering"`
+
+	// SortAttrs indicates whether or not to sort the attributes alphabetically before adding them to the message.
+	SortAttrs bool `json:"sort_attrs" yaml:"sort_attrs"`
+
+	// SourceFormatter is the name of the function to call to format the source code location.
+	SourceFormatter string `json:"source_formatter" yaml:"source_formatter"`
+
+	// SourcePrefix is the text to prefix the source information with in the output message.
+	SourcePrefix string `json:"source_prefix" yaml:"source_prefix"`
+
+	// SpecificAttrFormatter maps the full, dotted key of an attribute to the name of the function to call to format
+	// it.
+	//
+	// For example, mapping an attribute to the built-in "redact" formatter hides its value.
+	SpecificAttrFormatter map[string]string `json:"specific_attr_formatter" yaml:"specific_attr_formatter"`
+
+	// TimeDateToken is the format of the Slack date token used to display the time of the record.
+	TimeDateToken string `json:"time_date_token" yaml:"time_date_token"`
+
+	// TimeFormatter is the name of the function to call to format the time of the record.
+	TimeFormatter string `json:"time_formatter" yaml:"time_formatter"`
+
+	// TimePrefix is the text to prefix the record timestamp with in the output message.
+	TimePrefix string `json:"time_prefix" yaml:"time_prefix"`
+
+	// ValueFormats holds the formats used to display number, duration and time attribute values, matched in order.
+	ValueFormats []ValueFormatConfig `json:"value_formats" yaml:"value_formats"`
+
+	// ValueMaxDepth is the maximum depth of nested values pretty-printed as JSON.
+	ValueMaxDepth int `json:"value_max_depth" yaml:"value_max_depth"`
+
+	// ValueMaxSize is the maximum number of characters of a value pretty-printed as JSON.
+	ValueMaxSize int `json:"value_max_size" yaml:"value_max_size"`
+}
+
+// LevelConfig holds the configuration for displaying and routing the records of a level or range of levels.
+//
+// See LevelStyle for details on each setting.
+type LevelConfig struct {
+	// Channel is the channel records of the level are posted to by default.
+	Channel string `json:"channel" yaml:"channel"`
+
+	// Color is the color of the attachment used for records of the level when posting to Slack-compatible targets.
+	Color string `json:"color" yaml:"color"`
+
+	// Emoji is the emoji displayed before the level's label.
+	Emoji string `json:"emoji" yaml:"emoji"`
+
+	// Label is the name of the level.
+	Label string `json:"label" yaml:"label"`
+
+	// Level is the name or number of the level, or the lowest level of the range (eg: "error+2" or "10").
+	Level string `json:"level" yaml:"level"`
+
+	// MaxLevel is the name or number of the highest level of the range.
+	//
+	// If empty, only Level is defined.
+	MaxLevel string `json:"max_level" yaml:"max_level"`
+
+	// Mentions are the users, user groups and special mentions added to records of the level by default.
+	Mentions []string `json:"mentions" yaml:"mentions"`
+}
+
+// define validates the configuration and defines the level or range of levels in the registry.
+func (c LevelConfig) define(r *LevelRegistry) error {
+	min, err := ParseLevel(c.Level)
+	if err != nil {
+		return &ConfigError{Key: "level", Err: err}
+	}
+	max := min
+	if c.MaxLevel != "" {
+		if max, err = ParseLevel(c.MaxLevel); err != nil {
+			return &ConfigError{Key: "max_level", Err: err}
+		}
+	}
+	r.DefineRange(min, max, LevelStyle{
+		Channel:  c.Channel,
+		Color:    c.Color,
+		Emoji:    c.Emoji,
+		Label:    c.Label,
+		Mentions: c.Mentions,
+	})
+	return nil
+}
+
+// RateLimitConfig holds the configuration for limiting the number of records posted to Slack.
+//
+// See RateLimit for details on each setting.
+type RateLimitConfig struct {
+	// Interval is the period of time in which at most Messages messages are posted.
+	Interval ConfigDuration `json:"interval" yaml:"interval"`
+
+	// Messages is the maximum number of messages posted within any Interval.
+	//
+	// If zero, records are not limited.
+	Messages int `json:"messages" yaml:"messages"`
+}
+
+// RateLimit validates the configuration and converts it into a rate limit.
+//
+// If Messages is zero, nil is returned.
+func (c RateLimitConfig) RateLimit() (*RateLimit, error) {
+	if c.Messages == 0 {
+		return nil, nil
+	}
+	if c.Messages < 0 {
+		return nil, &ConfigError{Key: "messages", Err: errors.New("messages cannot be negative")}
+	}
+	if c.Interval <= 0 {
+		return nil, &ConfigError{Key: "interval", Err: errors.New("interval must be greater than zero")}
+	}
+	return &RateLimit{
+		Interval: time.Duration(c.Interval),
+		Messages: c.Messages,
+	}, nil
+}
+
+// RetryConfig holds the configuration for retrying messages which could not be posted to Slack.
+//
+// See RetryPolicy for details on each setting.
+type RetryConfig struct {
+	// Backoff is the amount of time to wait before the first retry, which is doubled after each retry.
+	Backoff ConfigDuration `json:"backoff" yaml:"backoff"`
+
+	// MaxAttempts is the maximum number of times to attempt posting a message, including the first attempt.
+	//
+	// If zero, messages are not retried.
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts"`
+
+	// MaxBackoff is the maximum amount of time to wait between retries.
+	MaxBackoff ConfigDuration `json:"max_backoff" yaml:"max_backoff"`
+}
+
+// RetryPolicy validates the configuration and converts it into a retry policy.
+//
+// If MaxAttempts is zero, nil is returned.
+func (c RetryConfig) RetryPolicy() (*RetryPolicy, error) {
+	if c.MaxAttempts == 0 {
+		return nil, nil
+	}
+	if c.MaxAttempts < 0 {
+		return nil, &ConfigError{Key: "max_attempts", Err: errors.New("attempts cannot be negative")}
+	}
+	if c.Backoff < 0 {
+		return nil, &ConfigError{Key: "backoff", Err: errors.New("backoff cannot be negative")}
+	}
+	if c.MaxBackoff < 0 {
+		return nil, &ConfigError{Key: "max_backoff", Err: errors.New("backoff cannot be negative")}
+	}
+	return &RetryPolicy{
+		Backoff:     time.Duration(c.Backoff),
+		MaxAttempts: c.MaxAttempts,
+		MaxBackoff:  time.Duration(c.MaxBackoff),
+	}, nil
+}
+
+// ValueFormatConfig holds the configuration for formatting the values of attributes whose keys match a pattern.
+//
+// See ValueFormat for details on each setting.
+type ValueFormatConfig struct {
+	// ByteSizes formats integer and float values as byte sizes using binary units.
+	ByteSizes bool `json:"byte_sizes" yaml:"byte_sizes"`
+
+	// DateToken is the format of the Slack date token used to display time values.
+	DateToken string `json:"date_token" yaml:"date_token"`
+
+	// DurationPrecision is the unit duration values are rounded to.
+	DurationPrecision ConfigDuration `json:"duration_precision" yaml:"duration_precision"`
+
+	// FloatPrecision is the number of digits displayed after the decimal point of float values.
+	FloatPrecision int `json:"float_precision" yaml:"float_precision"`
+
+	// KeyPattern is the regular expression matched against the full key of the attribute.
+	KeyPattern string `json:"key_pattern" yaml:"key_pattern"`
+
+	// RelativeTime displays time values relative to the time of the record.
+	RelativeTime bool `json:"relative_time" yaml:"relative_time"`
+
+	// ThousandsSeparator is inserted between each group of thousands of integer and float values.
+	ThousandsSeparator string `json:"thousands_separator" yaml:"thousands_separator"`
+
+	// TimeLayout is the layout used to display time values.
+	TimeLayout string `json:"time_layout" yaml:"time_layout"`
+
+	// Timezone is the name of the IANA timezone (eg: America/New_York) time values are converted to.
+	Timezone string `json:"timezone" yaml:"timezone"`
+}
+
+// ValueFormat validates the configuration and converts it into a value format.
+func (c ValueFormatConfig) ValueFormat() (ValueFormat, error) {
+	vf := ValueFormat{
+		ByteSizes:          c.ByteSizes,
+		DateToken:          c.DateToken,
+		DurationPrecision:  time.Duration(c.DurationPrecision),
+		FloatPrecision:     c.FloatPrecision,
+		KeyPattern:         c.KeyPattern,
+		RelativeTime:       c.RelativeTime,
+		ThousandsSeparator: c.ThousandsSeparator,
+		TimeLayout:         c.TimeLayout,
+	}
+	if _, err := regexp.Compile(c.KeyPattern); err != nil {
+		return vf, &ConfigError{Key: "key_pattern", Err: err}
+	}
+	if c.DurationPrecision < 0 {
+		return vf, &ConfigError{Key: "duration_precision", Err: errors.New("precision cannot be negative")}
+	}
+	if c.Timezone != "" {
+		location, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return vf, &ConfigError{Key: "timezone", Err: err}
+		}
+		vf.TimeLocation = location
+	}
+	return vf, nil
+}
+
+// DefaultConfig returns a configuration holding the default settings for the handler and formatter.
+func DefaultConfig() Config {
+	return Config{
+		Formatter: FormatterConfig{
+			IgnoreAttrs:           []string{},
+			IncludeAttrs:          true,
+			LevelFormatter:        "default",
+			SortAttrs:             true,
+			SourceFormatter:       "default",
+			SourcePrefix:          SlackMessageFormatterSourcePrefix,
+			SpecificAttrFormatter: map[string]string{},
+			TimeFormatter:         "default",
+			TimePrefix:            SlackMessageFormatterTimePrefix,
+		},
+		Level:           "info",
+		ShutdownTimeout: ConfigDuration(DefaultShutdownTimeout),
+		fileSecrets:     &fileSecrets{},
+	}
+}
+
+// LoadConfig loads the configuration from the default settings, the given file and the environment, in that order.
+//
+// If path is empty, no file is loaded. Environment variables are read using the given prefix; if the prefix is empty,
+// DefaultConfigEnvPrefix is used. The resulting configuration is validated before it is returned.
+func LoadConfig(path, envPrefix string) (Config, error) {
+	c := DefaultConfig()
+	if path != "" {
+		if err := c.LoadFile(path); err != nil {
+			return c, err
+		}
+	}
+	if err := c.LoadEnv(envPrefix); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+// LoadEnv overrides any settings which are set in the environment.
+//
+// If the prefix is empty, DefaultConfigEnvPrefix is used.
+func (c *Config) LoadEnv(prefix string) error {
+	if prefix == "" {
+		prefix = DefaultConfigEnvPrefix
+	}
+	return loadConfigEnv(reflect.ValueOf(c).Elem(), prefix, "")
+}
+
+// LoadFile overrides any settings which are set in the given YAML or JSON file.
+//
+// The format of the file is determined by its extension: .json files are parsed as JSON and any other file is parsed
+// as YAML.
+func (c *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	format := ConfigFormatYAML
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = ConfigFormatJSON
+	}
+	if err := c.Parse(data, format); err != nil {
+		return fmt.Errorf("failed to parse configuration file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// Parse overrides any settings which are set in the given YAML or JSON data.
+//
+// Unknown settings are treated as an error. Any error caused by an unknown setting or a setting of the wrong type is
+// a *ConfigError naming the setting.
+func (c *Config) Parse(data []byte, format ConfigFormat) error {
+	switch format {
+	case ConfigFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(c); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) && typeErr.Field != "" {
+				return &ConfigError{
+					Err: fmt.Errorf("cannot use %s as %s", typeErr.Value, typeErr.Type.String()),
+					Key: typeErr.Field,
+				}
+			}
+			// the decoder does not report the path of unknown fields, only their name
+			if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+				if key, err := strconv.Unquote(field); err == nil {
+					return &ConfigError{Key: key, Err: errors.New("unknown setting")}
+				}
+			}
+			return err
+		}
+	case ConfigFormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			var typeErr *yaml.TypeError
+			if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+				return yamlConfigError(data, typeErr.Errors[0])
+			}
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported configuration format '%s'", format)
+	}
+	return nil
+}
+
+// Validate checks the configuration for errors.
+//
+// Any error returned is a *ConfigError naming the invalid setting.
+func (c Config) Validate() error {
+	_, err := c.HandlerOptions()
+	return err
+}
+
+// HandlerOptions validates the configuration and converts it into a set of options for the handler.
+//
+// Any error returned is a *ConfigError naming the invalid setting.
+func (c Config) HandlerOptions() (SlackHandlerOptions, error) {
+	opts := DefaultSlackHandlerOptions()
+	webhookURL := c.WebhookURL
+	if c.WebhookURLFile != "" {
+		var err error
+		if webhookURL, err = c.fileSecrets.load(c.WebhookURLFile); err != nil {
+			return opts, &ConfigError{Key: "webhook_url_file", Err: err}
+		}
+	} else if webhookURL.IsZero() {
+		return opts, &ConfigError{Key: "webhook_url", Err: errors.New("webhook URL is required and cannot be empty")}
+	}
+	if err := CompatibilityProfile(c.Compatibility).validate(); err != nil {
+		return opts, &ConfigError{Key: "compatibility", Err: err}
+	}
+	level, err := ParseLevel(c.Level)
+	if err != nil {
+		return opts, &ConfigError{Key: "level", Err: err}
+	}
+	if c.HTTPTimeout < 0 {
+		return opts, &ConfigError{Key: "http_timeout", Err: errors.New("timeout cannot be negative")}
+	}
+	rateLimit, err := c.RateLimit.RateLimit()
+	if err != nil {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			configErr.Key = "rate_limit." + configErr.Key
+		}
+		return opts, err
+	}
+	retry, err := c.Retry.RetryPolicy()
+	if err != nil {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			configErr.Key = "retry." + configErr.Key
+		}
+		return opts, err
+	}
+	formatterOpts, err := c.Formatter.FormatterOptions()
+	if err != nil {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			configErr.Key = "formatter." + configErr.Key
+		}
+		return opts, err
+	}
+
+	opts.Compatibility = CompatibilityProfile(c.Compatibility)
+	opts.EnableAsync = c.EnableAsync
+	if c.HTTPTimeout > 0 {
+		opts.HTTPClient = &http.Client{Timeout: time.Duration(c.HTTPTimeout)}
+	}
+	opts.Level = newLevelVar(level)
+	opts.RateLimit = rateLimit
+	opts.RecordFormatter = NewSlackMessageFormatter(formatterOpts)
+	opts.Retry = retry
+	opts.ShutdownTimeout = time.Duration(c.ShutdownTimeout)
+	opts.WebhookURL = webhookURL
+	return opts, nil
+}
+
+// yamlConfigError converts an error reported by the YAML decoder, such as "line 3: field foo not found in type
+// slogxslack.Config", into a *ConfigError naming the setting found on that line of the data.
+//
+// If the setting cannot be found, the error is returned without a key.
+func yamlConfigError(data []byte, msg string) error {
+	err := errors.New(msg)
+	var line int
+	if _, scanErr := fmt.Sscanf(msg, "line %d:", &line); scanErr != nil {
+		return err
+	}
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil {
+		return err
+	}
+	key, ok := yamlKeyAtLine(&doc, line, "")
+	if !ok {
+		return err
+	}
+	return &ConfigError{Key: key, Err: err}
+}
+
+// yamlKeyAtLine returns the full, dotted key of the deepest setting whose key or value is on the given line of the
+// node.
+func yamlKeyAtLine(node *yaml.Node, line int, prefix string) (string, bool) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			if key, ok := yamlKeyAtLine(n, line, prefix); ok {
+				return key, true
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			key := k.Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if found, ok := yamlKeyAtLine(v, line, key); ok {
+				return found, true
+			}
+			if k.Line == line || v.Line == line {
+				return key, true
+			}
+		}
+	case yaml.SequenceNode:
+		for i, n := range node.Content {
+			key := fmt.Sprintf("%s[%d]", prefix, i)
+			if found, ok := yamlKeyAtLine(n, line, key); ok {
+				return found, true
+			}
+			if n.Kind == yaml.ScalarNode && n.Line == line {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// fileSecrets holds the secrets loaded from files by a configuration, which are shared by its copies so that
+// validating the configuration and creating the handler's options do not load the same file again.
+type fileSecrets struct {
+	mu      sync.Mutex
+	secrets map[string]Secret
+}
+
+// load returns the secret loaded from the given file, loading it if it has not been loaded already.
+//
+// If the receiver is nil, such as for a configuration which was not created using DefaultConfig(), the file is
+// loaded every time.
+func (fs *fileSecrets) load(path string) (Secret, error) {
+	if fs == nil {
+		return SecretFromFile(path, 0)
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if s, ok := fs.secrets[path]; ok {
+		return s, nil
+	}
+	s, err := SecretFromFile(path, 0)
+	if err != nil {
+		return Secret{}, err
+	}
+	if fs.secrets == nil {
+		fs.secrets = map[string]Secret{}
+	}
+	fs.secrets[path] = s
+	return s, nil
+}
+
+// FormatterOptions validates the configuration and converts it into a set of options for the formatter.
+//
+// Any error returned is a *ConfigError naming the invalid setting.
+func (c FormatterConfig) FormatterOptions() (SlackMessageFormatterOptions, error) {
+	var err error
+	opts := DefaultSlackMessageFormatterOptions()
+	opts.ApplicationIconURL = c.ApplicationIconURL
+	opts.ApplicationName = c.ApplicationName
+	opts.AttrOrder = append([]string{}, c.AttrOrder...)
+	opts.AttrRendering = AttrRendering(c.AttrRendering)
+	opts.HeaderAttrs = append([]string{}, c.HeaderAttrs...)
+	if c.Enrich || len(c.EnrichmentLabels) > 0 {
+		e := Enrichment{}
+		if c.Enrich {
+			e = DetectEnrichment()
+		}
+		e.Labels = map[string]string{}
+		for k, v := range c.EnrichmentLabels {
+			e.Labels[k] = v
+		}
+		opts.Enrichment = &e
+	}
+	opts.IncludeAttrs = c.IncludeAttrs
+	opts.IncludeSource = c.IncludeSource
+	opts.KeyAttrs = append([]string{}, c.KeyAttrs...)
+	opts.Layout = MessageLayout(c.Layout)
+	opts.MessageRendering = MessageRendering(c.MessageRendering)
+	opts.SortAttrs = c.SortAttrs
+	opts.SourcePrefix = c.SourcePrefix
+	opts.TimeDateToken = c.TimeDateToken
+	opts.TimePrefix = c.TimePrefix
+	opts.ValueMaxDepth = c.ValueMaxDepth
+	opts.ValueMaxSize = c.ValueMaxSize
+
+	if err := opts.AttrRendering.validate(); err != nil {
+		return opts, &ConfigError{Key: "attr_rendering", Err: err}
+	}
+	if err := opts.Layout.validate(); err != nil {
+		return opts, &ConfigError{Key: "layout", Err: err}
+	}
+	if err := opts.MessageRendering.validate(); err != nil {
+		return opts, &ConfigError{Key: "message_rendering", Err: err}
+	}
+	if len(c.Levels) > 0 {
+		opts.Levels = DefaultLevelRegistry()
+		for i, lc := range c.Levels {
+			if err := lc.define(opts.Levels); err != nil {
+				var configErr *ConfigError
+				if errors.As(err, &configErr) {
+					configErr.Key = fmt.Sprintf("levels[%d].%s", i, configErr.Key)
+				}
+				return opts, err
+			}
+		}
+	}
+	for i, vfc := range c.ValueFormats {
+		vf, err := vfc.ValueFormat()
+		if err != nil {
+			var configErr *ConfigError
+			if errors.As(err, &configErr) {
+				configErr.Key = fmt.Sprintf("value_formats[%d].%s", i, configErr.Key)
+			}
+			return opts, err
+		}
+		opts.ValueFormats = append(opts.ValueFormats, vf)
+	}
+	if c.ValueMaxDepth < 0 {
+		return opts, &ConfigError{Key: "value_max_depth", Err: errors.New("depth cannot be negative")}
+	}
+	if c.ValueMaxSize < 0 {
+		return opts, &ConfigError{Key: "value_max_size", Err: errors.New("size cannot be negative")}
+	}
+	for i, p := range c.IgnoreAttrs {
+		if _, err := regexp.Compile(p); err != nil {
+			return opts, &ConfigError{Key: fmt.Sprintf("ignore_attrs[%d]", i), Err: err}
+		}
+	}
+	opts.IgnoreAttrs = append([]string{}, c.IgnoreAttrs...)
+	for i, p := range c.AllowAttrs {
+		if _, err := regexp.Compile(p); err != nil {
+			return opts, &ConfigError{Key: fmt.Sprintf("allow_attrs[%d]", i), Err: err}
+		}
+	}
+	opts.AllowAttrs = append([]string{}, c.AllowAttrs...)
+	if c.IncludeAttrsLevel != "" {
+		level, err := ParseLevel(c.IncludeAttrsLevel)
+		if err != nil {
+			return opts, &ConfigError{Key: "include_attrs_level", Err: err}
+		}
+		opts.IncludeAttrsLevel = level
+	}
+
+	if c.AttrFormatter != "" {
+		if opts.AttrFormatter, err = registry.attrFormatter(c.AttrFormatter); err != nil {
+			return opts, &ConfigError{Key: "attr_formatter", Err: err}
+		}
+	}
+	if c.LevelFormatter != "" {
+		if opts.LevelFormatter, err = registry.levelFormatter(c.LevelFormatter); err != nil {
+			return opts, &ConfigError{Key: "level_formatter", Err: err}
+		}
+	}
+	if c.SourceFormatter != "" {
+		if opts.SourceFormatter, err = registry.sourceFormatter(c.SourceFormatter); err != nil {
+			return opts, &ConfigError{Key: "source_formatter", Err: err}
+		}
+	}
+	if c.TimeFormatter != "" {
+		if opts.TimeFormatter, err = registry.timeFormatter(c.TimeFormatter); err != nil {
+			return opts, &ConfigError{Key: "time_formatter", Err: err}
+		}
+	}
+	opts.SpecificAttrFormatter = map[string]formatter.FormatAttrFn{}
+	for key, name := range c.SpecificAttrFormatter {
+		fn, err := registry.attrFormatter(name)
+		if err != nil {
+			return opts, &ConfigError{Key: fmt.Sprintf("specific_attr_formatter.%s", key), Err: err}
+		}
+		opts.SpecificAttrFormatter[key] = fn
+	}
+	return opts, nil
+}
+
+// NewSlackHandlerFromConfig creates a new handler object using the given configuration.
+func NewSlackHandlerFromConfig(c Config) (*slackHandler, error) {
+	opts, err := c.HandlerOptions()
+	if err != nil {
+		return nil, err
+	}
+	return NewSlackHandler(opts)
+}
+
+// ParseLevel parses the name of a level, including the additional levels defined by slogx.
+//
+// Names are case-insensitive and may include an offset (eg: error+2 or INFO-1). Numeric levels are also accepted.
+func ParseLevel(name string) (slog.Level, error) {
+	return levels.Parse(name)
+}
+
+// loadConfigEnv sets the fields of the given struct from any matching environment variables.
+func loadConfigEnv(v reflect.Value, prefix, keyPrefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if keyPrefix != "" {
+			key = keyPrefix + "." + name
+		}
+		fieldValue := v.Field(i)
+		_, isText := fieldValue.Addr().Interface().(encoding.TextUnmarshaler)
+		if field.Type.Kind() == reflect.Struct && !isText {
+			if err := loadConfigEnv(fieldValue, prefix, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		envName := prefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		value, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+		if err := setConfigValue(fieldValue, value); err != nil {
+			return &ConfigError{Key: key, Source: envName, Err: err}
+		}
+	}
+	return nil
+}
+
+// setConfigValue sets the given field from the string value of an environment variable.
+func setConfigValue(v reflect.Value, value string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported setting type %s", v.Type().String())
+		}
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		items := map[string]string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			k, val, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("invalid KEY=VALUE pair '%s'", item)
+			}
+			items[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package slogxslack_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "slack.yaml")
+	data := []byte(`
+level: warn
+shutdown_timeout: 5s
+rate_limit:
+  interval: 1m
+  messages: 20
+formatter:
+  application_name: from-file
+  ignore_attrs: ["^internal\\."]
+  specific_attr_formatter:
+    user.password: redact
+  time_formatter: rfc3339_utc
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write configuration file: %s", err.Error())
+	}
+	t.Setenv("SLOGX_SLACK_WEBHOOK_URL", server.WebhookURL())
+	t.Setenv("SLOGX_SLACK_FORMATTER_APPLICATION_NAME", "from-env")
+	t.Setenv("SLOGX_SLACK_FORMATTER_VALUE_MAX_DEPTH", "3")
+	t.Setenv("SLOGX_SLACK_RETRY_MAX_ATTEMPTS", "4")
+
+	c, err := slogxslack.LoadConfig(path, "")
+	if err != nil {
+		t.Fatalf("failed to load configuration: %s", err.Error())
+	}
+	if c.Formatter.ApplicationName != "from-env" {
+		t.Errorf("expected the environment to override the file, got %q", c.Formatter.ApplicationName)
+	}
+	if c.Formatter.ValueMaxDepth != 3 {
+		t.Errorf("expected a maximum value depth of 3, got %d", c.Formatter.ValueMaxDepth)
+	}
+	if time.Duration(c.ShutdownTimeout) != 5*time.Second {
+		t.Errorf("expected a 5s shutdown timeout, got %s", time.Duration(c.ShutdownTimeout))
+	}
+	opts, err := c.HandlerOptions()
+	if err != nil {
+		t.Fatalf("failed to create handler options: %s", err.Error())
+	}
+	if opts.Level.Level() != slogx.LevelWarn.Level() {
+		t.Errorf("expected level warn, got %s", opts.Level.Level())
+	}
+	if opts.RateLimit == nil || opts.RateLimit.Messages != 20 || opts.RateLimit.Interval != time.Minute {
+		t.Errorf("expected a rate limit of 20 messages per minute, got %+v", opts.RateLimit)
+	}
+	if opts.Retry == nil || opts.Retry.MaxAttempts != 4 {
+		t.Errorf("expected a retry policy with 4 attempts, got %+v", opts.Retry)
+	}
+}
+
+func TestConfigErrorsNameKey(t *testing.T) {
+	tests := map[string]struct {
+		data   string
+		format slogxslack.ConfigFormat
+		key    string
+	}{
+		"level": {
+			data:   `{"webhook_url": "http://localhost", "level": "verbose"}`,
+			format: slogxslack.ConfigFormatJSON,
+			key:    "level",
+		},
+		"time_formatter": {
+			data:   "webhook_url: http://localhost\nformatter:\n  time_formatter: sundial\n",
+			format: slogxslack.ConfigFormatYAML,
+			key:    "formatter.time_formatter",
+		},
+		"ignore_attrs": {
+			data:   "webhook_url: http://localhost\nformatter:\n  ignore_attrs: ['ok', '(']\n",
+			format: slogxslack.ConfigFormatYAML,
+			key:    "formatter.ignore_attrs[1]",
+		},
+		"levels": {
+			data:   "webhook_url: http://localhost\nformatter:\n  levels:\n    - level: critical\n",
+			format: slogxslack.ConfigFormatYAML,
+			key:    "formatter.levels[0].level",
+		},
+		"json_type": {
+			data:   `{"webhook_url": "http://localhost", "enable_async": "yes"}`,
+			format: slogxslack.ConfigFormatJSON,
+			key:    "enable_async",
+		},
+		"json_unknown": {
+			data:   `{"webhook_url": "http://localhost", "routing": {}}`,
+			format: slogxslack.ConfigFormatJSON,
+			key:    "routing",
+		},
+		"yaml_type": {
+			data:   "webhook_url: http://localhost\nformatter:\n  value_max_size: lots\n",
+			format: slogxslack.ConfigFormatYAML,
+			key:    "formatter.value_max_size",
+		},
+		"yaml_unknown": {
+			data:   "webhook_url: http://localhost\nformatter:\n  levels:\n    - level: error\n      colour: red\n",
+			format: slogxslack.ConfigFormatYAML,
+			key:    "formatter.levels[0].colour",
+		},
+		"rate_limit": {
+			data:   "webhook_url: http://localhost\nrate_limit:\n  messages: 5\n",
+			format: slogxslack.ConfigFormatYAML,
+			key:    "rate_limit.interval",
+		},
+		"retry": {
+			data:   "webhook_url: http://localhost\nretry:\n  max_attempts: 3\n  backoff: -1s\n",
+			format: slogxslack.ConfigFormatYAML,
+			key:    "retry.backoff",
+		},
+		"webhook_url": {
+			data:   "level: info\n",
+			format: slogxslack.ConfigFormatYAML,
+			key:    "webhook_url",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := slogxslack.DefaultConfig()
+			err := c.Parse([]byte(tc.data), tc.format)
+			if err == nil {
+				err = c.Validate()
+			}
+			var configErr *slogxslack.ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("expected a ConfigError, got: %v", err)
+			}
+			if configErr.Key != tc.key {
+				t.Errorf("expected key %q, got %q", tc.key, configErr.Key)
+			}
+		})
+	}
+
+	c := slogxslack.DefaultConfig()
+	if err := c.Parse([]byte("webhook_url: http://localhost\nrouting: {}\n"), slogxslack.ConfigFormatYAML); err == nil {
+		t.Error("expected an error for an unknown setting")
+	}
+
+	t.Setenv("SLOGX_SLACK_ENABLE_ASYNC", "maybe")
+	var configErr *slogxslack.ConfigError
+	if err := c.LoadEnv(""); !errors.As(err, &configErr) || configErr.Source != "SLOGX_SLACK_ENABLE_ASYNC" {
+		t.Errorf("expected a ConfigError naming the environment variable, got: %v", err)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slogx.Level{
+		"trace":   slogx.LevelTrace,
+		"NOTICE":  slogx.LevelNotice,
+		"warning": slogx.LevelWarn,
+		"error+2": slogx.LevelError + 2,
+		"info-1":  slogx.LevelInfo - 1,
+		"12":      slogx.Level(12),
+	}
+	for name, expected := range tests {
+		level, err := slogxslack.ParseLevel(name)
+		if err != nil {
+			t.Errorf("failed to parse level %q: %s", name, err.Error())
+		} else if level != expected.Level() {
+			t.Errorf("expected level %q to be %d, got %d", name, expected, level)
+		}
+	}
+}
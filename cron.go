@@ -0,0 +1,152 @@
+package slogxslack
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed standard 5-field cron expression.
+type cronSchedule struct {
+	dom     uint64
+	domStar bool
+	dow     uint64
+	dowStar bool
+	hour    uint64
+	minute  uint64
+	month   uint64
+}
+
+// cronField describes the allowed range of a single cron field.
+type cronField struct {
+	max  int
+	min  int
+	name string
+}
+
+// cronFields holds the fields of a cron expression in order.
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// parseCron parses a standard 5-field cron expression (minute, hour, day of month, month and day of week).
+//
+// Each field may be "*", a number, a range ("1-5"), a step ("*/15" or "1-30/5") or a comma-separated list of these.
+// Sunday may be specified as either 0 or 7.
+func parseCron(expr string) (*cronSchedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields", expr, len(cronFields))
+	}
+	bits := make([]uint64, len(parts))
+	for i, part := range parts {
+		b, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &cronSchedule{
+		dom:     bits[2],
+		domStar: parts[2] == "*",
+		dow:     bits[4],
+		dowStar: parts[4] == "*",
+		hour:    bits[1],
+		minute:  bits[0],
+		month:   bits[3],
+	}, nil
+}
+
+// parseCronField parses a single field of a cron expression into a bit set of the allowed values.
+func parseCronField(expr string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepExpr, field.name)
+			}
+		}
+
+		start, end := field.min, field.max
+		if rangeExpr != "*" {
+			first, last, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if start, err = strconv.Atoi(first); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", first, field.name)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(last); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s field", last, field.name)
+				}
+			} else if hasStep {
+				end = field.max
+			}
+		}
+		if start < field.min || end > field.max || start > end {
+			return 0, fmt.Errorf("value %q out of range in %s field (%d-%d)", rangeExpr, field.name, field.min,
+				field.max)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// matchesDay determines whether or not the schedule matches the day containing the given time.
+//
+// As with standard cron, if both the day of month and day of week are restricted, a day matches if either field
+// matches.
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// prev returns the start of the latest minute matched by the schedule which is at or before the given time and after
+// the given limit.
+//
+// Rather than checking every minute, months, days and hours which do not match are skipped entirely.
+func (c *cronSchedule) prev(t, limit time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute)
+	for t.After(limit) {
+		var next time.Time
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			next = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+		case !c.matchesDay(t):
+			next = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			next = t.Add(-time.Duration(t.Minute()+1) * time.Minute)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			// skip to the previous allowed minute within the hour, or the end of the previous hour
+			m := bits.Len64(c.minute&(1<<uint(t.Minute())-1)) - 1
+			next = t.Add(-time.Duration(t.Minute()-m) * time.Minute)
+		default:
+			return t, true
+		}
+
+		// always move backwards, even if the start of the month or day is ambiguous due to daylight saving time
+		if !next.Before(t) {
+			next = t.Add(-time.Minute)
+		}
+		t = next
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,146 @@
+package slogxslack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+const (
+	// EnvPodName is the environment variable the name of the Kubernetes pod is read from, typically set using the
+	// downward API (fieldRef: metadata.name).
+	EnvPodName = "POD_NAME"
+
+	// EnvPodNamespace is the environment variable the namespace of the Kubernetes pod is read from, typically set
+	// using the downward API (fieldRef: metadata.namespace).
+	EnvPodNamespace = "POD_NAMESPACE"
+
+	// shortRevisionLength is the number of characters of the VCS revision displayed in messages.
+	shortRevisionLength = 12
+)
+
+// FormatEnrichmentFn is a function which formats the process metadata displayed at the bottom of every message.
+type FormatEnrichmentFn func(ctx context.Context, level slog.Leveler, e Enrichment) (string, error)
+
+// Enrichment holds the process metadata displayed at the bottom of every message.
+//
+// Use DetectEnrichment() to fill in the metadata from the running process and its environment.
+type Enrichment struct {
+	// GoVersion is the version of Go the program was built with (eg: go1.21.5).
+	GoVersion string
+
+	// Hostname is the name of the host the program is running on.
+	Hostname string
+
+	// Labels holds any custom static labels, such as the environment or team, to display after the process metadata.
+	//
+	// Labels are displayed in order of their keys.
+	Labels map[string]string
+
+	// Namespace is the namespace of the Kubernetes pod the program is running in.
+	Namespace string
+
+	// PID is the ID of the program's process.
+	//
+	// If zero, the process ID is not displayed.
+	PID int
+
+	// Pod is the name of the Kubernetes pod the program is running in.
+	Pod string
+
+	// Revision is the VCS revision the program was built from, suffixed with "+dirty" if there were uncommitted
+	// changes.
+	Revision string
+
+	// Version is the version of the program's main module.
+	Version string
+}
+
+// DetectEnrichment returns the metadata of the running process.
+//
+// The hostname and process ID are read from the operating system, the Go version, module version and VCS revision
+// from the program's build information and the Kubernetes pod and namespace from the EnvPodName and EnvPodNamespace
+// environment variables. Any metadata which cannot be detected is left empty.
+func DetectEnrichment() Enrichment {
+	e := Enrichment{
+		GoVersion: runtime.Version(),
+		Namespace: os.Getenv(EnvPodNamespace),
+		PID:       os.Getpid(),
+		Pod:       os.Getenv(EnvPodName),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		e.Hostname = hostname
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.GoVersion != "" {
+			e.GoVersion = info.GoVersion
+		}
+		if info.Main.Version != "" && info.Main.Version != "(devel)" {
+			e.Version = info.Main.Version
+		}
+		modified := false
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				e.Revision = s.Value
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if e.Revision != "" && modified {
+			e.Revision += "+dirty"
+		}
+	}
+	return e
+}
+
+// fields returns the names and values of the metadata which is set, followed by the labels.
+func (e Enrichment) fields() [][2]string {
+	fields := [][2]string{}
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+	add("host", e.Hostname)
+	if e.PID != 0 {
+		add("pid", fmt.Sprintf("%d", e.PID))
+	}
+	add("go", e.GoVersion)
+	add("version", e.Version)
+	revision := e.Revision
+	if i := strings.Index(revision, "+"); i > shortRevisionLength {
+		revision = revision[:shortRevisionLength] + revision[i:]
+	} else if i == -1 && len(revision) > shortRevisionLength {
+		revision = revision[:shortRevisionLength]
+	}
+	add("revision", revision)
+	add("namespace", e.Namespace)
+	add("pod", e.Pod)
+
+	keys := make([]string, 0, len(e.Labels))
+	for k := range e.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add(k, e.Labels[k])
+	}
+	return fields
+}
+
+// formatSlackMessageEnrichmentDefault formats the metadata on a single line (eg: "host: `web-1`  |  pid: `42`").
+//
+// Long VCS revisions are shortened to their first 12 characters.
+func formatSlackMessageEnrichmentDefault(ctx context.Context, level slog.Leveler, e Enrichment) (string, error) {
+	texts := []string{}
+	for _, f := range e.fields() {
+		texts = append(texts, fmt.Sprintf("%s: `%s`", EscapeMrkdwn(f[0]), strings.ReplaceAll(f[1], "`", "'")))
+	}
+	return strings.Join(texts, "  |  "), nil
+}
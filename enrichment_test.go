@@ -0,0 +1,52 @@
+package slogxslack_test
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+func TestDetectEnrichment(t *testing.T) {
+	t.Setenv(slogxslack.EnvPodName, "api-0")
+	t.Setenv(slogxslack.EnvPodNamespace, "billing")
+
+	e := slogxslack.DetectEnrichment()
+	if e.Pod != "api-0" || e.Namespace != "billing" {
+		t.Errorf("expected the pod and namespace from the environment, got %q and %q", e.Pod, e.Namespace)
+	}
+	if e.PID != os.Getpid() || e.GoVersion != runtime.Version() {
+		t.Errorf("expected the process ID and Go version, got %d and %q", e.PID, e.GoVersion)
+	}
+	if hostname, _ := os.Hostname(); e.Hostname != hostname {
+		t.Errorf("expected hostname %q, got %q", hostname, e.Hostname)
+	}
+}
+
+func TestEnrichmentConfig(t *testing.T) {
+	c := slogxslack.DefaultConfig()
+	c.Formatter.EnrichmentLabels = map[string]string{"env": "prod"}
+	opts, err := c.Formatter.FormatterOptions()
+	if err != nil {
+		t.Fatalf("failed to create formatter options: %s", err.Error())
+	}
+	if opts.Enrichment == nil || opts.Enrichment.Labels["env"] != "prod" || opts.Enrichment.Hostname != "" {
+		t.Fatalf("expected only the labels without enrich, got %+v", opts.Enrichment)
+	}
+
+	message, err := slogxslack.NewSlackMessageFormatter(opts).FormatRecord(context.Background(), time.Now(),
+		slogx.LevelInfo, 0, "deployed", nil)
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	footer := message.Blocks.BlockSet[len(message.Blocks.BlockSet)-1].(*slack.ContextBlock)
+	if text := footer.ContextElements.Elements[0].(slack.TextBlockObject).Text; !strings.Contains(text, "env: `prod`") {
+		t.Errorf("expected the labels in a context block at the bottom of the message, got %q", text)
+	}
+}
@@ -0,0 +1,63 @@
+package slogxslack
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ContextAttrsGroup is the name of the group holding the attributes returned by the built-in context extractors.
+const ContextAttrsGroup = "context"
+
+// ContextExtractorFn is a function which extracts request-scoped attributes from the context passed to the handler.
+type ContextExtractorFn func(ctx context.Context) []slog.Attr
+
+// ExtractContextDeadline returns the deadline of the context and the time remaining until it, rounded to the
+// millisecond, in the "context" group (eg: context.deadline and context.deadline_remaining).
+//
+// If the context has no deadline, no attributes are returned. The remaining time is negative once the deadline has
+// passed.
+func ExtractContextDeadline(ctx context.Context) []slog.Attr {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	return []slog.Attr{
+		slog.Group(ContextAttrsGroup,
+			slog.Time("deadline", deadline),
+			slog.Duration("deadline_remaining", time.Until(deadline).Round(time.Millisecond)),
+		),
+	}
+}
+
+// ExtractContextCause returns the reason the context was canceled and, if it differs, the cause passed to the
+// context's cancel function, in the "context" group (eg: context.error and context.cause).
+//
+// If the context has not been canceled, no attributes are returned.
+func ExtractContextCause(ctx context.Context) []slog.Attr {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	attrs := []any{slog.String("error", err.Error())}
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(err, cause) {
+		attrs = append(attrs, slog.String("cause", cause.Error()))
+	}
+	return []slog.Attr{slog.Group(ContextAttrsGroup, attrs...)}
+}
+
+// extractContextAttrs runs each of the handler's context extractors against the context, returning the combined
+// attributes.
+func (h slackHandler) extractContextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil || len(h.options.ContextExtractors) == 0 {
+		return nil
+	}
+	attrs := []slog.Attr{}
+	for _, fn := range h.options.ContextExtractors {
+		if fn != nil {
+			attrs = append(attrs, fn(ctx)...)
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,69 @@
+package slogxslack_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+	"go.innotegrity.dev/slogx/formatter"
+)
+
+// requestIDContext is the context key holding the request ID in tests.
+type requestIDContext struct{}
+
+func TestContextExtractors(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	formatterOpts := slogxslack.DefaultSlackMessageFormatterOptions()
+	formatterOpts.SpecificAttrFormatter = map[string]formatter.FormatAttrFn{
+		"tenant": func(ctx context.Context, level slog.Leveler, group, key string, value slog.Value) (string,
+			slog.Value, error) {
+			return key, slog.StringValue(slogxslack.RedactedValue), nil
+		},
+	}
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		ContextExtractors: []slogxslack.ContextExtractorFn{
+			func(ctx context.Context) []slog.Attr {
+				id, _ := ctx.Value(requestIDContext{}).(string)
+				return []slog.Attr{slog.String("request_id", id), slog.String("tenant", "acme")}
+			},
+			slogxslack.ExtractContextDeadline,
+			slogxslack.ExtractContextCause,
+		},
+		RecordFormatter: slogxslack.NewSlackMessageFormatter(formatterOpts),
+		WebhookURL:      slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler).WithGroup("job")
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), requestIDContext{}, "r-1"), time.Hour)
+	defer cancel()
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	cancelCause(errors.New("client went away"))
+	logger.ErrorContext(ctx, "request aborted", "step", 2)
+	slog.New(handler).With("request_id", "r-2").InfoContext(context.Background(), "handler attrs win")
+
+	messages := server.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	m := messages[0]
+	for _, text := range []string{"*request_id*: `r-1`", "*tenant*: `" + slogxslack.RedactedValue + "`",
+		"*context.deadline_remaining*", "*context.error*: `context canceled`",
+		"*context.cause*: `client went away`", "*job.step*: `2`"} {
+		slacktest.AssertContainsText(t, m, text)
+	}
+	if m.ContainsText("job.request_id") || m.ContainsText("acme") {
+		t.Errorf("expected extracted attributes at the top level and redacted, got %q", m.Texts())
+	}
+	if m := messages[1]; !m.ContainsText("*request_id*: `r-2`") || m.ContainsText("context.") {
+		t.Errorf("expected the handler's attribute to replace the extracted one, got %q", m.Texts())
+	}
+}
@@ -2,12 +2,13 @@ package slogxslack
 
 import (
 	"context"
-	"encoding"
 	"fmt"
 	"log/slog"
+	"reflect"
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/slack-go/slack"
 	"go.innotegrity.dev/slogx"
@@ -20,6 +21,14 @@ const (
 
 	// SlackMessageFormatterTimeAttr is the default text to prepend when outputting the time of the record.
 	SlackMessageFormatterTimePrefix = "Occurred at:\t"
+
+	// DefaultTimeDateToken is the suggested Slack date token format for the TimeDateToken option, which displays the
+	// date and time of the record (eg: "Jan 2, 2024 at 3:04:05 PM").
+	DefaultTimeDateToken = "{date_short_pretty} at {time_secs}"
+
+	// timeDateTokenFallbackLayout is the layout of the UTC time displayed when a Slack client cannot display a date
+	// token.
+	timeDateTokenFallbackLayout = "2006-01-02 15:04:05 MST"
 )
 
 // SlackMessageFormatter describes the interface a formatter which outputs a record to a Slack message must implement.
@@ -29,6 +38,20 @@ type SlackMessageFormatter interface {
 	FormatRecord(context.Context, time.Time, slogx.Level, uintptr, string, []slog.Attr) (*slack.WebhookMessage, error)
 }
 
+// FormatLinksFn is a function which returns the links to display beneath the message.
+type FormatLinksFn func(ctx context.Context, level slog.Leveler) ([]SlackMessageLink, error)
+
+// SlackMessageLink is a link displayed beneath the message in the Slack output.
+type SlackMessageLink struct {
+	// Text is the text to display for the link.
+	//
+	// If this is empty, the URL is displayed instead.
+	Text string
+
+	// URL is the URL to link to.
+	URL string
+}
+
 // slackMessageFormatterOptionsContext can be used to retrieve the options used by the formatter from the context.
 type slackMessageFormatterOptionsContext struct{}
 
@@ -44,6 +67,13 @@ type SlackMessageFormatterOptions struct {
 	// If this is empty, no application name is shown.
 	ApplicationName string
 
+	// AllowAttrs is a list of regular expressions to use for matching the only attributes which should be printed.
+	//
+	// Expressions are matched against the full, dotted key of each attribute (eg: GROUP.ATTRIBUTE). Attributes which
+	// are allowed may still be ignored by IgnoreAttrs. If any regular expression does not compile, it is simply
+	// ignored. If empty, every attribute is allowed.
+	AllowAttrs []string
+
 	// AttrFormatter is the middleware formatting function to call to format any attribute.
 	//
 	// Attribute values should be resolved by the handler before formatting. Any value returned by the formatter should
@@ -52,6 +82,44 @@ type SlackMessageFormatterOptions struct {
 	// If nil, attributes are simply printed unchanged.
 	AttrFormatter formatter.FormatAttrFn
 
+	// AttrOrder is a list of the full, dotted keys of the attributes to display first, in the order listed (eg:
+	// "service", "user_id" and "error").
+	//
+	// The remaining attributes follow in their original order, which is alphabetical if SortAttrs is true. If empty,
+	// the attributes are not reordered.
+	AttrOrder []string
+
+	// AttrRendering determines how attributes, particularly those nested within groups, are displayed.
+	//
+	// If empty, AttrRenderingFlat is used.
+	AttrRendering AttrRendering
+
+	// Enrichment holds the process metadata, such as the hostname, version and custom labels, to display in a context
+	// block at the bottom of every message.
+	//
+	// Use DetectEnrichment() to detect the metadata of the running process. If nil, no metadata is displayed.
+	Enrichment *Enrichment
+
+	// EnrichmentFormatter is the middleware formatting function to call to format the process metadata.
+	//
+	// If the function returns an empty string, the context block is left out. If nil, each piece of metadata which is
+	// set is displayed on a single line.
+	EnrichmentFormatter FormatEnrichmentFn
+
+	// HeaderFormatter is the middleware formatting function to call to format the title of the header block when
+	// using MessageLayoutHeader.
+	//
+	// The title is trimmed to MaxHeaderLength characters. If nil, the title is made up of the level's emoji and name
+	// followed by the first line of the message.
+	HeaderFormatter FormatHeaderFn
+
+	// HeaderAttrs is a list of the full, dotted keys of the attributes whose values are promoted to the title of the
+	// header block when using MessageLayoutHeader.
+	//
+	// Header attributes are appended to the title in the order listed and are not repeated with the rest of the
+	// attributes. If empty, no attributes are added to the title.
+	HeaderAttrs []string
+
 	// IgnoreAttrs is a list of regular expressions to use for matching attributes which should not be printed.
 	//
 	// Note that this only applies to attributes and not defined parts like the level, message, source or time.
@@ -62,19 +130,56 @@ type SlackMessageFormatterOptions struct {
 	// IncludeAttrs indicates whether or not to include attributes in the Slack message.
 	IncludeAttrs bool
 
+	// IncludeAttrsLevel is the minimum level of the records whose attributes are included in the Slack message when
+	// IncludeAttrs is true (eg: slogx.LevelWarn).
+	//
+	// Attributes promoted to the header or summary are displayed regardless. If nil, attributes are included at every
+	// level.
+	IncludeAttrsLevel slog.Leveler
+
 	// IncludeSource indicates whether or not to include source file location information in the Slack mesage.
 	IncludeSource bool
 
+	// KeyAttrs is a list of the full, dotted keys of the attributes to summarize on a single line (eg: "service", "env"
+	// and "host").
+	//
+	// The summary is displayed beneath the header when using MessageLayoutHeader, or beneath the application name and
+	// level otherwise. Key attributes are displayed in the order listed and are not repeated with the rest of the
+	// attributes. They are displayed even if IncludeAttrs is false. If empty, no summary is displayed.
+	KeyAttrs []string
+
+	// Layout determines how the blocks of the message are arranged.
+	//
+	// If empty, MessageLayoutDefault is used.
+	Layout MessageLayout
+
 	// LevelFormatter is the middleware formatting function to call to format the level.
 	//
 	// If nil, the level is printed using FormatLevelValueDefault().
 	LevelFormatter formatter.FormatLevelValueFn
 
+	// Levels is the registry holding the emoji, label, color and default channel and mentions of each level.
+	//
+	// If nil, DefaultLevelRegistry() is used.
+	Levels *LevelRegistry
+
+	// LinksFormatter is the middleware formatting function to call to retrieve any links to display beneath the
+	// message.
+	//
+	// If nil, no links are displayed.
+	LinksFormatter FormatLinksFn
+
 	// MessageFormatter is the middlware formatting function to call to format the message.
 	//
-	// If nil, the message is printed as-is.
+	// The formatted message is then rendered according to MessageRendering. If nil, the message is rendered as-is.
 	MessageFormatter formatter.FormatMessageValueFn
 
+	// MessageRendering determines how the message is rendered.
+	//
+	// If empty, MessageRenderingEscaped is used, so any markup in the message is displayed as it was logged. Use
+	// MessageRenderingMrkdwn for messages which intentionally contain Slack markup.
+	MessageRendering MessageRendering
+
 	// SortAttrs indicates whether or not to sort the attributes alphabetically before adding them to the message.
 	SortAttrs bool
 
@@ -101,6 +206,11 @@ type SlackMessageFormatterOptions struct {
 	// If nil or if the attribute does not exist in the map, the default is to fall back to the AttrFormatter function.
 	SpecificAttrFormatter map[string]formatter.FormatAttrFn
 
+	// SummaryFormatter is the middleware formatting function to call to format the summary of the key attributes.
+	//
+	// If nil, each key attribute is formatted like any other attribute and the results are joined on a single line.
+	SummaryFormatter FormatSummaryFn
+
 	// TimePrefix is the text to prefix the record timestamp with in the output message.
 	//
 	// If this is empty, the default value of "Occurred at:\t" is used.
@@ -110,11 +220,47 @@ type SlackMessageFormatterOptions struct {
 	//
 	// If nil, the time is printed using FormatTimeValueDefault().
 	TimeFormatter formatter.FormatTimeValueFn
+
+	// TimeDateToken is the format of the Slack date token used to display the time of the record (eg:
+	// DefaultTimeDateToken).
+	//
+	// Slack displays date tokens in each reader's own timezone. Readers whose Slack client cannot display the token
+	// see the time in UTC, including the date, instead. If empty, the time is displayed using TimeFormatter.
+	TimeDateToken string
+
+	// ValueFormatters holds the functions used to render attribute values of specific Go types.
+	//
+	// The key for the map is the type of the value (eg: reflect.TypeOf(MyType{})). Formatters are only used for
+	// values whose kind is slog.KindAny, after AttrFormatter and SpecificAttrFormatter have been applied. If nil or if
+	// the value's type does not exist in the map, maps, slices, arrays, structs and json.Marshaler values are
+	// pretty-printed as JSON in a code block and other values are printed inline.
+	ValueFormatters map[reflect.Type]FormatValueFn
+
+	// ValueFormats holds the formats used to display int, uint, float, duration and time attribute values.
+	//
+	// The first format whose KeyPattern matches the attribute's key is used, so more specific patterns should be listed
+	// first. If nil or if no format matches, floats are displayed using the shortest representation, times are
+	// displayed in UTC using the RFC3339 layout and durations are displayed using their String() function.
+	ValueFormats []ValueFormat
+
+	// ValueMaxDepth is the maximum depth of nested values pretty-printed as JSON.
+	//
+	// Values nested any deeper are replaced by a placeholder. If zero, DefaultValueMaxDepth is used.
+	ValueMaxDepth int
+
+	// ValueMaxSize is the maximum number of characters of a value pretty-printed as JSON, after any characters Slack
+	// requires to be escaped have been replaced by HTML entities.
+	//
+	// Larger values are truncated. If zero, DefaultValueMaxSize is used. Values are always truncated to fit within
+	// Slack's limit of 3000 characters per block.
+	ValueMaxSize int
 }
 
 // DefaultSlackMessageFormatterOptions returns a default set of options for the Slack message formatter.
 func DefaultSlackMessageFormatterOptions() SlackMessageFormatterOptions {
 	return SlackMessageFormatterOptions{
+		EnrichmentFormatter:   formatSlackMessageEnrichmentDefault,
+		HeaderFormatter:       formatSlackMessageHeaderDefault,
 		IgnoreAttrs:           []string{},
 		IncludeAttrs:          true,
 		LevelFormatter:        formatSlackMessageLevelDefault,
@@ -123,9 +269,7 @@ func DefaultSlackMessageFormatterOptions() SlackMessageFormatterOptions {
 		SourceFormatter:       formatter.FormatSourceValueDefault,
 		SpecificAttrFormatter: map[string]formatter.FormatAttrFn{},
 		TimePrefix:            SlackMessageFormatterTimePrefix,
-		TimeFormatter: func(ctx context.Context, level slog.Leveler, t time.Time) (string, error) {
-			return t.Local().Format("03:04:05PM MST"), nil
-		},
+		TimeFormatter:         formatSlackMessageTimeDefault,
 	}
 }
 
@@ -151,7 +295,9 @@ func (o *SlackMessageFormatterOptions) AddToContext(ctx context.Context) context
 // slackMessageFormatter formats records for output as Slack messages.
 type slackMessageFormatter struct {
 	// unexported variables
+	allowedAttrPatterns []*regexp.Regexp
 	ignoredAttrPatterns []*regexp.Regexp
+	valueFormats        []compiledValueFormat
 	options             SlackMessageFormatterOptions
 }
 
@@ -175,12 +321,32 @@ func NewSlackMessageFormatter(opts SlackMessageFormatterOptions) *slackMessageFo
 		ignoredAttrPatterns: []*regexp.Regexp{},
 		options:             opts,
 	}
+	if len(opts.AllowAttrs) > 0 {
+		f.allowedAttrPatterns = []*regexp.Regexp{}
+		for _, p := range opts.AllowAttrs {
+			regex, err := regexp.Compile(p)
+			if err == nil {
+				f.allowedAttrPatterns = append(f.allowedAttrPatterns, regex)
+			}
+		}
+	}
 	for _, p := range opts.IgnoreAttrs {
 		regex, err := regexp.Compile(p)
 		if err == nil {
 			f.ignoredAttrPatterns = append(f.ignoredAttrPatterns, regex)
 		}
 	}
+	for _, vf := range opts.ValueFormats {
+		compiled := compiledValueFormat{format: vf}
+		if vf.KeyPattern != "" {
+			regex, err := regexp.Compile(vf.KeyPattern)
+			if err != nil {
+				continue
+			}
+			compiled.pattern = regex
+		}
+		f.valueFormats = append(f.valueFormats, compiled)
+	}
 	return f
 }
 
@@ -189,14 +355,53 @@ func NewSlackMessageFormatter(opts SlackMessageFormatterOptions) *slackMessageFo
 //
 // By default, duration values in attributes are formatted using the String() function and time values are formatted
 // in UTC time using the RFC3339 layout.
+//
+// If the timestamp is zero, the time is not included in the message. Likewise, if the program counter is zero, the
+// source location is not included in the message.
+//
+// Any mentions added to the context using WithMentions(), or otherwise the default mentions of the level, are added
+// just before the message and to the message's notification text. The message is posted to the default channel of
+// the level, if any, unless a channel is added to the context using WithChannel().
+//
+// When using MessageLayoutHeader, the message starts with a header block and a summary of the key attributes instead
+// of a divider. Attributes listed in the AttrOrder option are displayed before the remaining attributes. Any process
+// metadata set in the Enrichment option is displayed last.
 func (f *slackMessageFormatter) FormatRecord(ctx context.Context, timestamp time.Time, level slogx.Level, pc uintptr,
 	msg string, attrs []slog.Attr) (*slack.WebhookMessage, error) {
 
 	var err error
 	var strVal string
 	handlerCtx := f.options.AddToContext(ctx)
+	if f.options.MessageFormatter != nil {
+		if msg, err = f.options.MessageFormatter(handlerCtx, level, msg); err != nil {
+			return nil, err
+		}
+	}
 
-	// initialize the message
+	// flatten and order the attributes, separating any attributes promoted to the header or summary
+	headerLayout := f.options.Layout == MessageLayoutHeader
+	includeAttrs := f.includeAttrs(level)
+	var headerAttrs, keyAttrs []slog.Attr
+	var groupPaths map[string][]string
+	if includeAttrs || len(f.options.KeyAttrs) > 0 || (headerLayout && len(f.options.HeaderAttrs) > 0) {
+		if f.options.SortAttrs {
+			attrs = slogx.SortAttrs(attrs)
+		}
+		if f.options.AttrRendering == AttrRenderingGrouped {
+			groupPaths = attrGroupPaths(attrs)
+		}
+		attrs = f.orderAttrs(slogx.FlattenAttrs(attrs))
+		if headerLayout {
+			headerAttrs, attrs = takeAttrs(f.options.HeaderAttrs, attrs)
+		}
+		keyAttrs, attrs = takeAttrs(f.options.KeyAttrs, attrs)
+	}
+	summary, err := f.summaryBlock(handlerCtx, level, timestamp, keyAttrs)
+	if err != nil {
+		return nil, err
+	}
+
+	// initialize the message, starting with the header and summary when using the header layout
 	message := &slack.WebhookMessage{
 		Blocks: &slack.Blocks{
 			BlockSet: []slack.Block{
@@ -206,6 +411,19 @@ func (f *slackMessageFormatter) FormatRecord(ctx context.Context, timestamp time
 			},
 		},
 	}
+	style := f.options.Levels.Lookup(level)
+	message.Channel = style.Channel
+	title := ""
+	if headerLayout {
+		var header slack.Block
+		if header, title, err = f.headerBlock(handlerCtx, level, msg, headerAttrs); err != nil {
+			return nil, err
+		}
+		message.Blocks.BlockSet = []slack.Block{header}
+		if summary != nil {
+			message.Blocks.BlockSet = append(message.Blocks.BlockSet, summary)
+		}
+	}
 
 	// add the application name and level context
 	appLevelContextElements := []slack.MixedElement{}
@@ -235,20 +453,19 @@ func (f *slackMessageFormatter) FormatRecord(ctx context.Context, timestamp time
 		Text: strVal,
 	})
 	message.Blocks.BlockSet = append(message.Blocks.BlockSet, slack.NewContextBlock("", appLevelContextElements...))
+	if !headerLayout && summary != nil {
+		message.Blocks.BlockSet = append(message.Blocks.BlockSet, summary)
+	}
 
 	// add the time and source (if requested)
-	timeSourceText := f.options.TimePrefix
-	if f.options.TimeFormatter != nil {
-		strVal, err = f.options.TimeFormatter(handlerCtx, level, timestamp)
-	} else {
-		strVal, err = formatter.FormatTimeValueDefault(handlerCtx, level, timestamp)
-	}
-	if err != nil {
-		return nil, err
+	timeSourceLines := []string{}
+	if !timestamp.IsZero() {
+		if strVal, err = f.formatRecordTime(handlerCtx, level, timestamp); err != nil {
+			return nil, err
+		}
+		timeSourceLines = append(timeSourceLines, f.options.TimePrefix+strVal)
 	}
-	timeSourceText += strVal
-	if f.options.IncludeSource {
-		timeSourceText = fmt.Sprintf("%s\n%s", timeSourceText, f.options.SourcePrefix)
+	if f.options.IncludeSource && pc != 0 {
 		if f.options.SourceFormatter != nil {
 			strVal, err = f.options.SourceFormatter(handlerCtx, level, pc)
 		} else {
@@ -257,80 +474,143 @@ func (f *slackMessageFormatter) FormatRecord(ctx context.Context, timestamp time
 		if err != nil {
 			return nil, err
 		}
-		timeSourceText += strVal
+		timeSourceLines = append(timeSourceLines, f.options.SourcePrefix+strVal)
+	}
+	if len(timeSourceLines) > 0 {
+		message.Blocks.BlockSet = append(message.Blocks.BlockSet, slack.NewContextBlock("", slack.TextBlockObject{
+			Type: slack.MarkdownType,
+			Text: strings.Join(timeSourceLines, "\n"),
+		}))
 	}
-	message.Blocks.BlockSet = append(message.Blocks.BlockSet, slack.NewContextBlock("", slack.TextBlockObject{
-		Type: slack.MarkdownType,
-		Text: timeSourceText,
-	}))
 
-	// add the message
+	// add the message, preceded by any mentions from the context, unless the header already contains all of it
 	message.Blocks.BlockSet = append(message.Blocks.BlockSet,
 		slack.DividerBlock{
 			Type: slack.MBTDivider,
 		},
-		slack.SectionBlock{
+	)
+	overrides := GetMessageOverridesFromContext(ctx)
+	if len(overrides.Mentions) == 0 {
+		overrides.Mentions = style.Mentions
+	}
+	if mentions := overrides.mentionText(); mentions != "" {
+		message.Text = mentions + " " + f.options.MessageRendering.mrkdwn(msg)
+		message.Blocks.BlockSet = append(message.Blocks.BlockSet, slack.SectionBlock{
 			Type: slack.MBTSection,
 			Text: &slack.TextBlockObject{
 				Type: slack.MarkdownType,
-				Text: msg,
+				Text: mentions,
 			},
-		},
-	)
+		})
+	}
+	if !headerLayout || !strings.Contains(title, msg) {
+		message.Blocks.BlockSet = append(message.Blocks.BlockSet, f.options.MessageRendering.block(msg))
+	}
 
-	// add attributes (if requested)
-	if f.options.IncludeAttrs {
-		if f.options.SortAttrs {
-			attrs = slogx.SortAttrs(attrs)
+	// add any links (if requested)
+	if f.options.LinksFormatter != nil {
+		links, err := f.options.LinksFormatter(handlerCtx, level)
+		if err != nil {
+			return nil, err
 		}
-		flattenedAttrs := slogx.FlattenAttrs(attrs)
-		for _, attr := range flattenedAttrs {
-			element, err := f.attrToElement(handlerCtx, level, attr.Key, attr.Value)
-			if err != nil {
-				return nil, err
+		if len(links) > 0 {
+			linkTexts := []string{}
+			for _, l := range links {
+				if l.URL == "" {
+					continue
+				}
+				if l.Text == "" {
+					linkTexts = append(linkTexts, fmt.Sprintf("<%s>", l.URL))
+				} else {
+					linkTexts = append(linkTexts, fmt.Sprintf("<%s|%s>", l.URL, l.Text))
+				}
 			}
-			if element != nil {
-				message.Blocks.BlockSet = append(message.Blocks.BlockSet, slack.NewContextBlock("", element))
+			if len(linkTexts) > 0 {
+				message.Blocks.BlockSet = append(message.Blocks.BlockSet, slack.NewContextBlock("",
+					slack.TextBlockObject{
+						Type: slack.MarkdownType,
+						Text: strings.Join(linkTexts, "  |  "),
+					}))
 			}
 		}
 	}
+
+	// add attributes (if requested)
+	if includeAttrs {
+		blocks, err := f.attrBlocks(handlerCtx, level, timestamp, attrs, groupPaths)
+		if err != nil {
+			return nil, err
+		}
+		message.Blocks.BlockSet = append(message.Blocks.BlockSet, blocks...)
+	}
+
+	// add the process metadata (if requested)
+	if f.options.Enrichment != nil {
+		if f.options.EnrichmentFormatter != nil {
+			strVal, err = f.options.EnrichmentFormatter(handlerCtx, level, *f.options.Enrichment)
+		} else {
+			strVal, err = formatSlackMessageEnrichmentDefault(handlerCtx, level, *f.options.Enrichment)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strVal != "" {
+			message.Blocks.BlockSet = append(message.Blocks.BlockSet, slack.NewContextBlock("",
+				slack.TextBlockObject{
+					Type: slack.MarkdownType,
+					Text: strVal,
+				}))
+		}
+	}
 	return message, nil
 }
 
-// attrToElement converts the given attribute into a Slack context element.
-func (f slackMessageFormatter) attrToElement(ctx context.Context, level slog.Leveler, attrKey string,
-	attrValue slog.Value) (slack.MixedElement, error) {
+// levelRegistry returns the formatter's level registry.
+//
+// If the Levels option is nil, the default level registry is returned.
+func (f slackMessageFormatter) levelRegistry() *LevelRegistry {
+	if f.options.Levels == nil {
+		return defaultLevelRegistry
+	}
+	return f.options.Levels
+}
 
-	// ignore the attribute if the key matches
-	for _, p := range f.ignoredAttrPatterns {
-		if p.MatchString(attrKey) {
-			return nil, nil
-		}
+// levelStyle returns the style of the level from the formatter's level registry.
+func (f slackMessageFormatter) levelStyle(level slog.Leveler) LevelStyle {
+	return f.options.Levels.Lookup(level)
+}
+
+// formatRecordTime formats a time belonging to the record, such as its timestamp, using either the TimeDateToken or
+// TimeFormatter option.
+func (f slackMessageFormatter) formatRecordTime(ctx context.Context, level slog.Leveler, t time.Time) (string,
+	error) {
+
+	if f.options.TimeDateToken != "" {
+		return slackDateToken(t, f.options.TimeDateToken, t.UTC().Format(timeDateTokenFallbackLayout)), nil
+	}
+	if f.options.TimeFormatter != nil {
+		return f.options.TimeFormatter(ctx, level, t)
 	}
+	return formatter.FormatTimeValueDefault(ctx, level, t)
+}
 
-	// extract the group name and attribute from the key
-	group := ""
-	actualAttrKey := attrKey
-	groupIndex := strings.LastIndex(attrKey, ".")
-	if groupIndex != -1 {
-		group = attrKey[:groupIndex]
-		actualAttrKey = attrKey[groupIndex+1:]
+// attrToElement converts the given attribute into a Slack context element.
+//
+// Values are formatted using the first of the ValueFormats options whose key pattern matches the attribute's key. The
+// record's timestamp is used to display times relative to the record. The indent is the number of characters the
+// caller adds in front of the text, which are kept free when truncating values rendered as JSON.
+func (f slackMessageFormatter) attrToElement(ctx context.Context, level slog.Leveler, timestamp time.Time,
+	attrKey string, attrValue slog.Value, indent int) (slack.MixedElement, error) {
+
+	// ignore the attribute if it is not selected
+	if !f.attrSelected(attrKey) {
+		return nil, nil
 	}
 
 	// format the attribute using any formatter functions first
-	formattedKey := attrKey
-	formattedValue := attrValue.Resolve()
-	var err error
-	if fn, ok := f.options.SpecificAttrFormatter[attrKey]; ok && fn != nil {
-		formattedKey, formattedValue, err = fn(ctx, level, group, actualAttrKey, formattedValue)
-		if err != nil {
-			return nil, err
-		}
-	} else if f.options.AttrFormatter != nil {
-		formattedKey, formattedValue, err = f.options.AttrFormatter(ctx, level, group, actualAttrKey, formattedValue)
-		if err != nil {
-			return nil, err
-		}
+	formattedKey, formattedValue, err := f.formatAttr(ctx, level, attrKey, attrValue)
+	if err != nil {
+		return nil, err
 	}
 
 	// format the key/value
@@ -342,51 +622,53 @@ func (f slackMessageFormatter) attrToElement(ctx context.Context, level slog.Lev
 		element.Text = fmt.Sprintf("*%s*: `%t`", formattedKey, formattedValue.Bool())
 	case slog.KindString:
 		element.Text = fmt.Sprintf("*%s*: `%s`", formattedKey, formattedValue.String())
-	case slog.KindDuration:
-		element.Text = fmt.Sprintf("*%s*: `%s`", formattedKey, formattedValue.Duration().String())
-	case slog.KindTime:
-		element.Text = fmt.Sprintf("*%s*: `%s`", formattedKey, formattedValue.Time().UTC().Format(time.RFC3339))
-	case slog.KindFloat64:
-		element.Text = fmt.Sprintf("*%s*: `%f`", formattedKey, formattedValue.Float64())
-	case slog.KindInt64:
-		element.Text = fmt.Sprintf("*%s*: `%d`", formattedKey, formattedValue.Int64())
-	case slog.KindUint64:
-		element.Text = fmt.Sprintf("*%s*: `%d`", formattedKey, formattedValue.Uint64())
+	case slog.KindDuration, slog.KindTime, slog.KindFloat64, slog.KindInt64, slog.KindUint64:
+		element.Text = fmt.Sprintf("*%s*: %s", formattedKey,
+			f.valueFormat(attrKey).formatScalarValue(formattedValue, timestamp))
 	case slog.KindGroup: // should never occur as the attrs have been flattened
 		element.Text = fmt.Sprintf("*%s*: `%+v`", formattedKey, formattedValue.Group())
 	default:
-		if tm, ok := formattedValue.Any().(encoding.TextMarshaler); ok {
-			output, err := tm.MarshalText()
-			if err != nil {
-				return nil, err
-			}
-			element.Text = fmt.Sprintf("*%s*: `%s`", formattedKey, string(output))
+		prefix := utf8.RuneCountInString(fmt.Sprintf("*%s*:\n", formattedKey))
+		text, err := f.formatAnyValue(ctx, level, attrKey, formattedValue.Any(), indent+prefix)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(text, "```") {
+			element.Text = fmt.Sprintf("*%s*:\n%s", formattedKey, text)
 		} else {
-			element.Text = fmt.Sprintf("*%s*: `%+v`", formattedKey, formattedValue.Any())
+			element.Text = fmt.Sprintf("*%s*: %s", formattedKey, text)
 		}
 	}
 	return element, nil
 }
 
-// formatSlackMessageLevelDeafult formats the level using an emoji prefix.
+// formatAttr formats the attribute with the given full, dotted key using the SpecificAttrFormatter or AttrFormatter
+// options.
+func (f slackMessageFormatter) formatAttr(ctx context.Context, level slog.Leveler, attrKey string,
+	attrValue slog.Value) (string, slog.Value, error) {
+
+	// extract the group name and attribute from the key
+	group := ""
+	actualAttrKey := attrKey
+	groupIndex := strings.LastIndex(attrKey, ".")
+	if groupIndex != -1 {
+		group = attrKey[:groupIndex]
+		actualAttrKey = attrKey[groupIndex+1:]
+	}
+
+	if fn, ok := f.options.SpecificAttrFormatter[attrKey]; ok && fn != nil {
+		return fn(ctx, level, group, actualAttrKey, attrValue.Resolve())
+	} else if f.options.AttrFormatter != nil {
+		return f.options.AttrFormatter(ctx, level, group, actualAttrKey, attrValue.Resolve())
+	}
+	return attrKey, attrValue.Resolve(), nil
+}
+
+// formatSlackMessageLevelDeafult formats the level using the emoji and label from the formatter's level registry.
 func formatSlackMessageLevelDefault(ctx context.Context, level slog.Leveler) (string, error) {
-	switch level {
-	case slogx.LevelTrace:
-		return ":eyes: trace", nil
-	case slogx.LevelDebug:
-		return ":ladybug: debug", nil
-	case slogx.LevelInfo:
-		return ":information_source: info", nil
-	case slogx.LevelNotice:
-		return ":grey_exclamation: notice", nil
-	case slogx.LevelWarn:
-		return ":warning: warn", nil
-	case slogx.LevelError:
-		return ":no_entry: error", nil
-	case slogx.LevelFatal:
-		return ":rotating_light: fatal", nil
-	case slogx.LevelPanic:
-		return ":sos: panic", nil
-	}
-	return fmt.Sprintf("%s", level), nil
+	style := GetSlackMessageFormatterOptionsFromContext(ctx).Levels.Lookup(level)
+	if style.Emoji == "" {
+		return style.Label, nil
+	}
+	return style.Emoji + " " + style.Label, nil
 }
@@ -0,0 +1,57 @@
+package slogxslack_test
+
+import (
+	"flag"
+	"testing"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func init() {
+	slacktest.RegisterUpdateFlag(flag.CommandLine)
+}
+
+func TestFormatterGolden(t *testing.T) {
+	tests := map[string]func(*slogxslack.SlackMessageFormatterOptions){
+		"default": func(o *slogxslack.SlackMessageFormatterOptions) {},
+		"application_source": func(o *slogxslack.SlackMessageFormatterOptions) {
+			o.ApplicationIconURL = "https://example.com/icon.png"
+			o.ApplicationName = "slogx"
+			o.IncludeSource = true
+		},
+		"date_token": func(o *slogxslack.SlackMessageFormatterOptions) {
+			o.TimeDateToken = slogxslack.DefaultTimeDateToken
+		},
+		"enrichment": func(o *slogxslack.SlackMessageFormatterOptions) {
+			o.Enrichment = &slogxslack.Enrichment{
+				GoVersion: "go1.21.5",
+				Hostname:  "web-1",
+				Labels:    map[string]string{"team": "payments", "env": "prod"},
+				Namespace: "billing",
+				PID:       42,
+				Pod:       "api-7d9f8-x2x4z",
+				Revision:  "0123456789abcdef0123456789abcdef01234567+dirty",
+				Version:   "v1.2.3",
+			}
+		},
+		"grouped": func(o *slogxslack.SlackMessageFormatterOptions) {
+			o.AttrRendering = slogxslack.AttrRenderingGrouped
+		},
+		"header": func(o *slogxslack.SlackMessageFormatterOptions) {
+			o.KeyAttrs = []string{"int64", "group.string", "bool"}
+			o.Layout = slogxslack.MessageLayoutHeader
+		},
+		"unsorted_ignored": func(o *slogxslack.SlackMessageFormatterOptions) {
+			o.IgnoreAttrs = []string{`^any_`, `\.nested\.`}
+			o.SortAttrs = false
+		},
+	}
+	for name, configure := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := slacktest.FixtureOptions(slogxslack.DefaultSlackMessageFormatterOptions())
+			configure(&opts)
+			slacktest.AssertFormatterGolden(t, "formatter_"+name, slogxslack.NewSlackMessageFormatter(opts))
+		})
+	}
+}
@@ -6,10 +6,10 @@ toolchain go1.21.1
 
 require (
 	github.com/slack-go/slack v0.12.3
-	go.innotegrity.dev/async v0.1.1
 	go.innotegrity.dev/errorx v1.0.15
 	go.innotegrity.dev/generic v0.1.1
 	go.innotegrity.dev/slogx v0.3.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,295 @@
+package slogxslack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+// AttrRendering determines how the formatter displays attributes, particularly those nested within groups.
+type AttrRendering string
+
+const (
+	// AttrRenderingFlat displays each attribute in its own context block, with attributes nested within groups
+	// displayed using their full, dotted keys (eg: GROUP.ATTRIBUTE).
+	AttrRenderingFlat AttrRendering = "flat"
+
+	// AttrRenderingGrouped keeps the structure of groups.
+	//
+	// Attributes which are not within a group are displayed in their own context block. Each top-level group is
+	// displayed as a section with the group's name as its heading, with the attributes of any nested groups indented
+	// beneath a sub-heading. The path of groups added to the handler using WithGroup() is displayed once, above the
+	// attributes, rather than being repeated in every key. Keys which contain dots but are not within a group are
+	// displayed as they are.
+	AttrRenderingGrouped AttrRendering = "grouped"
+)
+
+const (
+	// groupIndent is the text used to indent each level of attributes nested within groups.
+	//
+	// Em spaces are used as Slack does not display leading spaces consistently.
+	groupIndent = "\u2003\u2003"
+
+	// maxSectionText is the maximum number of characters Slack allows in the text of a section block.
+	maxSectionText = 3000
+)
+
+// groupPathContext can be used to retrieve the path of groups added to the handler from the context.
+type groupPathContext struct{}
+
+// GetGroupPathFromContext retrieves the path of groups added to the handler using WithGroup() from the context
+// passed to the formatter.
+//
+// If the handler has no groups, nil is returned.
+func GetGroupPathFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	if path, ok := ctx.Value(groupPathContext{}).([]string); ok {
+		return path
+	}
+	return nil
+}
+
+// withGroupPath returns a new context holding the path of the groups added to a handler, if there are any.
+func withGroupPath(ctx context.Context, goas []groupOrAttrs) context.Context {
+	path := []string{}
+	for _, goa := range goas {
+		if goa.group != "" {
+			path = append(path, goa.group)
+		}
+	}
+	if len(path) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, groupPathContext{}, path)
+}
+
+// validate determines whether or not the rendering is known.
+func (r AttrRendering) validate() error {
+	switch r {
+	case "", AttrRenderingFlat, AttrRenderingGrouped:
+		return nil
+	}
+	return fmt.Errorf("unknown attribute rendering %q", r)
+}
+
+// attrGroupPaths returns the names of the groups enclosing each attribute nested within a group, keyed by the
+// attribute's flattened key.
+//
+// The paths are collected before the attributes are flattened so that keys which merely contain dots are not
+// mistaken for groups.
+func attrGroupPaths(attrs []slog.Attr) map[string][]string {
+	paths := map[string][]string{}
+	var walk func(prefix string, path []string, attrs []slog.Attr)
+	walk = func(prefix string, path []string, attrs []slog.Attr) {
+		for _, attr := range attrs {
+			value := attr.Value.Resolve()
+			switch {
+			case value.Kind() == slog.KindGroup && attr.Key == "":
+				walk(prefix, path, value.Group())
+			case value.Kind() == slog.KindGroup:
+				walk(prefix+attr.Key+".", append(append([]string{}, path...), attr.Key), value.Group())
+			case len(path) > 0:
+				paths[prefix+attr.Key] = path
+			}
+		}
+	}
+	walk("", nil, attrs)
+	return paths
+}
+
+// attrBlocks returns the blocks displaying the flattened attributes according to the AttrRendering option.
+//
+// The paths hold the groups enclosing each attribute, as returned by attrGroupPaths(), and are only used when
+// grouping attributes.
+func (f slackMessageFormatter) attrBlocks(ctx context.Context, level slog.Leveler, timestamp time.Time,
+	attrs []slog.Attr, paths map[string][]string) ([]slack.Block, error) {
+
+	blocks := []slack.Block{}
+	grouped := f.options.AttrRendering == AttrRenderingGrouped
+	var handlerPath []string
+	if grouped {
+		handlerPath = GetGroupPathFromContext(ctx)
+	}
+	sections := map[string]*groupSection{}
+	pathUsed := false
+	for _, attr := range attrs {
+		// strip the handler's group path from the attribute's groups
+		var path []string
+		label := attr.Key
+		stripped := false
+		indent := 0
+		if grouped {
+			path = paths[attr.Key]
+			if len(path) > 0 {
+				label = attr.Key[len(strings.Join(path, "."))+1:]
+			}
+			if stripped = hasGroupPrefix(path, handlerPath); stripped {
+				path = path[len(handlerPath):]
+			}
+			if len(path) > 1 {
+				indent = (len(path) - 1) * utf8.RuneCountInString(groupIndent)
+			}
+		}
+
+		element, err := f.attrToElement(ctx, level, timestamp, attr.Key, attr.Value, indent)
+		if err != nil {
+			return nil, err
+		}
+		text, ok := element.(slack.TextBlockObject)
+		if !ok {
+			continue
+		}
+		if !grouped {
+			blocks = append(blocks, slack.NewContextBlock("", text))
+			continue
+		}
+		pathUsed = pathUsed || stripped
+		if len(path) == 0 {
+			text.Text = relabelAttrText(text.Text, attr.Key, label)
+			blocks = append(blocks, slack.NewContextBlock("", text))
+			continue
+		}
+
+		// add the attribute to the section of its top-level group
+		section, ok := sections[path[0]]
+		if !ok {
+			section = &groupSection{index: len(blocks), name: path[0]}
+			sections[path[0]] = section
+			blocks = append(blocks, nil)
+		}
+		section.add(path[1:], relabelAttrText(text.Text, attr.Key, label))
+	}
+	if !grouped {
+		return blocks, nil
+	}
+
+	// replace the placeholder of each group with its sections
+	result := []slack.Block{}
+	if pathUsed {
+		result = append(result, slack.NewContextBlock("", slack.TextBlockObject{
+			Type: slack.MarkdownType,
+			Text: "Group: `" + strings.Join(handlerPath, ".") + "`",
+		}))
+	}
+	indexes := map[int]*groupSection{}
+	for _, s := range sections {
+		indexes[s.index] = s
+	}
+	for i, b := range blocks {
+		if s, ok := indexes[i]; ok {
+			result = append(result, s.blocks()...)
+		} else {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+// hasGroupPrefix determines whether or not the path of groups starts with the given, non-empty prefix.
+func hasGroupPrefix(path, prefix []string) bool {
+	if len(prefix) == 0 || len(path) < len(prefix) {
+		return false
+	}
+	for i, name := range prefix {
+		if path[i] != name {
+			return false
+		}
+	}
+	return true
+}
+
+// groupSection holds the attributes of the section displaying a top-level group.
+type groupSection struct {
+	index int
+	name  string
+	root  groupNode
+}
+
+// groupNode holds the attributes and nested groups within a group, in the order in which they first appear.
+type groupNode struct {
+	children map[string]*groupNode
+	items    []groupItem
+}
+
+// groupItem is either the text of an attribute or a nested group within a groupNode.
+type groupItem struct {
+	group *groupNode
+	name  string
+	text  string
+}
+
+// add adds the text of an attribute within the given nested groups.
+//
+// Attributes are collected by their nested groups, so that each nested group is displayed once, beneath a single
+// heading, even if the attributes within it are not next to each other (eg: when reordered by the AttrOrder option).
+func (s *groupSection) add(nested []string, text string) {
+	node := &s.root
+	for _, name := range nested {
+		child, ok := node.children[name]
+		if !ok {
+			if node.children == nil {
+				node.children = map[string]*groupNode{}
+			}
+			child = &groupNode{}
+			node.children[name] = child
+			node.items = append(node.items, groupItem{group: child, name: name})
+		}
+		node = child
+	}
+	node.items = append(node.items, groupItem{text: text})
+}
+
+// lines returns the lines displaying the attributes within the group, indented according to their depth, with each
+// nested group preceded by its heading.
+func (n *groupNode) lines(depth int) []string {
+	lines := []string{}
+	for _, item := range n.items {
+		if item.group == nil {
+			lines = append(lines, strings.Repeat(groupIndent, depth)+item.text)
+			continue
+		}
+		lines = append(lines, strings.Repeat(groupIndent, depth)+"*"+item.name+"*")
+		lines = append(lines, item.group.lines(depth+1)...)
+	}
+	return lines
+}
+
+// blocks returns the section blocks displaying the group, split so that the text of each section fits within Slack's
+// limit.
+func (s *groupSection) blocks() []slack.Block {
+	blocks := []slack.Block{}
+	text := ""
+	for _, line := range append([]string{"*" + s.name + "*"}, s.root.lines(0)...) {
+		if text != "" && len(text)+len(line)+1 > maxSectionText {
+			blocks = append(blocks, slack.NewSectionBlock(
+				slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil))
+			text = ""
+		}
+		if text != "" {
+			text += "\n"
+		}
+		text += line
+	}
+	if text != "" {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil))
+	}
+	return blocks
+}
+
+// relabelAttrText replaces the full key at the start of an attribute's text with the given label.
+//
+// If the key was changed by an attribute formatter, the text is returned unchanged.
+func relabelAttrText(text, key, label string) string {
+	if strings.HasPrefix(text, "*"+key+"*") {
+		return "*" + label + "*" + text[len(key)+2:]
+	}
+	return text
+}
@@ -0,0 +1,107 @@
+package slogxslack_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestGroupedAttrRendering(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	formatterOpts := slogxslack.DefaultSlackMessageFormatterOptions()
+	formatterOpts.AttrRendering = slogxslack.AttrRenderingGrouped
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		RecordFormatter: slogxslack.NewSlackMessageFormatter(formatterOpts),
+		WebhookURL:      slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler).With("app", "billing").WithGroup("request")
+	logger.Error("charge failed", "id", "r-1", slog.Group("user", "id", "u-1", slog.Group("plan", "tier", "pro")))
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	m := messages[0]
+	slacktest.AssertContainsText(t, m, "Group: `request`")
+	slacktest.AssertContainsText(t, m, "*app*: `billing`")
+	slacktest.AssertContainsText(t, m, "*id*: `r-1`")
+	slacktest.AssertContainsText(t, m, "*user*\n*id*: `u-1`\n*plan*\n\u2003\u2003*tier*: `pro`")
+	if m.ContainsText("request.id") || m.ContainsText("user.plan.tier") {
+		t.Errorf("expected keys not to repeat the group path, got %q", m.Texts())
+	}
+}
+
+func TestGroupedAttrRenderingOrder(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	formatterOpts := slogxslack.DefaultSlackMessageFormatterOptions()
+	formatterOpts.AttrOrder = []string{"a.x.1", "a.y.1"}
+	formatterOpts.AttrRendering = slogxslack.AttrRenderingGrouped
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		RecordFormatter: slogxslack.NewSlackMessageFormatter(formatterOpts),
+		WebhookURL:      slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	slog.New(handler).Error("ordered",
+		slog.Group("a", slog.Group("x", "2", "two", "1", "one"), slog.Group("y", "1", "one")))
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	m := messages[0]
+	indent := "\u2003\u2003"
+	slacktest.AssertContainsText(t, m,
+		"*a*\n*x*\n"+indent+"*1*: `one`\n"+indent+"*2*: `two`\n*y*\n"+indent+"*1*: `one`")
+	if n := strings.Count(strings.Join(m.Texts(), "\n"), "*x*"); n != 1 {
+		t.Errorf("expected the nested group heading to be displayed once, got %d in %q", n, m.Texts())
+	}
+}
+
+func TestGroupedAttrRenderingDottedKeys(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	formatterOpts := slogxslack.DefaultSlackMessageFormatterOptions()
+	formatterOpts.AttrRendering = slogxslack.AttrRenderingGrouped
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		RecordFormatter: slogxslack.NewSlackMessageFormatter(formatterOpts),
+		WebhookURL:      slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler).WithGroup("request")
+	logger.Error("request failed", "http.method", "GET", slog.Group("user", "org.id", "o-1"))
+	slog.New(handler).Error("request failed", "request.id", "r-1")
+
+	messages := server.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	m := messages[0]
+	slacktest.AssertContainsText(t, m, "Group: `request`")
+	slacktest.AssertContainsText(t, m, "*http.method*: `GET`")
+	slacktest.AssertContainsText(t, m, "*user*\n*org.id*: `o-1`")
+	if m.ContainsText("*http*") || m.ContainsText("*org*") {
+		t.Errorf("expected dotted keys not to be displayed as groups, got %q", m.Texts())
+	}
+
+	// a dotted key matching the handler's group path is not within the group
+	m = messages[1]
+	slacktest.AssertContainsText(t, m, "*request.id*: `r-1`")
+	if m.ContainsText("Group: `request`") {
+		t.Errorf("expected no group path, got %q", m.Texts())
+	}
+}
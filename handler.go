@@ -5,51 +5,134 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/slack-go/slack"
-	"go.innotegrity.dev/async"
-	"go.innotegrity.dev/generic"
 	"go.innotegrity.dev/slogx"
 )
 
+// DefaultShutdownTimeout is the default amount of time Shutdown() waits for pending records to be delivered.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ErrLevelNotAdjustable is returned when attempting to change the level of a handler whose Level option is not a
+// *slog.LevelVar.
+var ErrLevelNotAdjustable = errors.New("handler level is not a *slog.LevelVar and cannot be changed")
+
 // slackHandlerOptionsContext can be used to retrieve the options used by the handler from the context.
 type slackHandlerOptionsContext struct{}
 
+// DeliveryObserver describes the interface an object which observes messages being posted to Slack must implement.
+//
+// Observers can be used to collect metrics or tracing information about the delivery of messages.
+type DeliveryObserver interface {
+	// StartDelivery is called just before the message is posted to Slack.
+	//
+	// The returned context is used when posting the message and is passed to EndDelivery.
+	StartDelivery(ctx context.Context, level slog.Level) context.Context
+
+	// EndDelivery is called once the message has been posted to Slack, whether or not the post was successful.
+	EndDelivery(ctx context.Context, level slog.Level, elapsed time.Duration, err error)
+}
+
 // SlackHandlerOptions holds the options for the Slack handler.
 type SlackHandlerOptions struct {
+	// Compatibility identifies the type of Slack-compatible webhook the WebhookURL belongs to.
+	//
+	// Targets other than Slack, such as Mattermost, Rocket.Chat and Discord's Slack-compatible endpoint, do not support
+	// Block Kit, so messages are converted into attachments using the target's markup before they are posted. If
+	// empty, CompatibilitySlack is used.
+	Compatibility CompatibilityProfile
+
+	// ContextExtractors is a list of functions used to extract request-scoped attributes, such as a request ID or
+	// tenant, from the context passed to Handle().
+	//
+	// Extracted attributes are added to the top level of the record's attributes before duplicates are removed, so an
+	// attribute with the same key added to the handler or record takes precedence. They are then used for incidents
+	// and formatted, including by any SpecificAttrFormatter, like any other attribute. See ExtractContextDeadline() and
+	// ExtractContextCause() for built-in extractors. If empty, no attributes are extracted.
+	ContextExtractors []ContextExtractorFn
+
+	// DeliveryObserver is notified before and after each message is posted to Slack.
+	//
+	// If nil, no observer is notified.
+	DeliveryObserver DeliveryObserver
+
 	// EnableAsync will execute the Handle() function in a separate goroutine.
 	//
 	// When async is enabled, you should be sure to call the Shutdown() function or use the slogx.Shutdown()
 	// function to ensure all goroutines are finished and any pending records have been written.
 	EnableAsync bool
 
+	// Heartbeat enables the heartbeat, which periodically posts a status message showing the application's uptime,
+	// the number of records handled and any delivery failures, and posts a final message when the handler is shut
+	// down.
+	//
+	// If nil, no heartbeat messages are posted.
+	Heartbeat *HeartbeatOptions
+
 	// HTTPClient allows for the use of a custom HTTP client for posting the webhook message.
 	//
 	// If nil, http.DefaultClient is used.
 	HTTPClient *http.Client
 
+	// Incidents enables incident tracking, where records belonging to the same incident are grouped into a single
+	// parent message and thread which is edited when the incident is resolved.
+	//
+	// If nil, incidents are not tracked and every record is posted as a separate message.
+	Incidents *IncidentOptions
+
 	// Level is the minimum log level to write to the handler.
 	//
-	// By default, the level will be set to slog.LevelInfo if not supplied.
+	// To change the level at runtime, such as through the handler returned by AdminHandler(), use a *slog.LevelVar.
+	// By default, the level will be set to a *slog.LevelVar with a level of slog.LevelInfo if not supplied.
 	Level slog.Leveler
 
+	// RateLimit limits the number of records posted to Slack by the handler and every handler derived from it.
+	//
+	// Records which exceed the limit are dropped. Heartbeat messages and the summaries of held records are not
+	// limited. If nil, records are not limited.
+	RateLimit *RateLimit
+
 	// RecordFormatter specifies the formatter to use to format the record before sending it to Slack.
 	//
-	// If no formatter is supplied, DefaultSlackMessageFormatter is used to format the output.
+	// If no formatter is supplied, DefaultSlackMessageFormatter is used to format the output. If the formatter returns
+	// a nil message, the record is dropped.
 	RecordFormatter SlackMessageFormatter
 
+	// Retry determines whether and how often posting a message to the webhook is retried when the post fails.
+	//
+	// If nil, messages are not retried.
+	Retry *RetryPolicy
+
+	// Schedule holds the windows of time, such as planned maintenance, during which the handler raises its minimum
+	// level, redirects records to a different webhook or holds records and posts a summary of them when the window
+	// ends.
+	//
+	// Windows can also be changed at runtime using SetScheduleWindow() and RemoveScheduleWindow(). If nil, no windows
+	// are active.
+	Schedule []ScheduleWindow
+
+	// ShutdownTimeout is the maximum amount of time Shutdown() waits for pending records to be delivered.
+	//
+	// If zero, DefaultShutdownTimeout is used. If negative, Shutdown() waits until all records have been delivered.
+	ShutdownTimeout time.Duration
+
 	// WebhookURL is the Slack webhook URL to use in order to send the message.
 	//
+	// The URL is a credential, so it is held as a Secret which redacts itself when printed or logged and is removed
+	// from any delivery errors returned by the handler. Use SecretFromFile() to load a URL which may be rotated.
+	//
 	// This is a required option.
-	WebhookURL string
+	WebhookURL Secret
 }
 
 // DefaultSlackHandlerOptions returns a default set of options for the handler.
 func DefaultSlackHandlerOptions() SlackHandlerOptions {
 	return SlackHandlerOptions{
 		HTTPClient:      http.DefaultClient,
-		Level:           slog.LevelInfo,
+		Level:           newLevelVar(slog.LevelInfo),
 		RecordFormatter: DefaultSlackMessageFormatter(),
+		ShutdownTimeout: DefaultShutdownTimeout,
 	}
 }
 
@@ -74,104 +157,301 @@ func (o *SlackHandlerOptions) AddToContext(ctx context.Context) context.Context
 
 // slackHandler is a log handler that writes records to Slack via a webhook.
 type slackHandler struct {
-	activeGroup string
-	attrs       []slog.Attr
-	futures     []async.Future
-	groups      []string
-	options     SlackHandlerOptions
+	goas      []groupOrAttrs
+	heartbeat *heartbeat
+	incidents *incidentTracker
+	lifecycle *handlerLifecycle
+	limiter   *rateLimiter
+	options   SlackHandlerOptions
+	schedule  *handlerSchedule
 }
 
 // NewSlackHandler creates a new handler object.
 func NewSlackHandler(opts SlackHandlerOptions) (*slackHandler, error) {
 	// validate required options
-	if opts.WebhookURL == "" {
+	if opts.WebhookURL.IsZero() {
 		return nil, errors.New("webhook URL is required and cannot be empty")
 	}
 
+	if err := opts.Compatibility.validate(); err != nil {
+		return nil, err
+	}
+	if opts.RateLimit != nil {
+		if err := opts.RateLimit.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Retry != nil {
+		if err := opts.Retry.validate(); err != nil {
+			return nil, err
+		}
+		retry := *opts.Retry
+		opts.Retry = &retry
+	}
+
 	// set default options
+	if opts.Compatibility == "" {
+		opts.Compatibility = CompatibilitySlack
+	}
 	if opts.HTTPClient == nil {
 		opts.HTTPClient = http.DefaultClient
 	}
 	if opts.Level == nil {
-		opts.Level = slog.LevelInfo
+		opts.Level = newLevelVar(slog.LevelInfo)
+	}
+	if opts.ShutdownTimeout == 0 {
+		opts.ShutdownTimeout = DefaultShutdownTimeout
 	}
 
 	// create the handler
-	return &slackHandler{
-		attrs:   []slog.Attr{},
-		futures: []async.Future{},
-		groups:  []string{},
-		options: opts,
-	}, nil
+	schedule, err := newHandlerSchedule(opts.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	opts.Schedule = nil
+	h := &slackHandler{
+		goas:      []groupOrAttrs{},
+		lifecycle: newHandlerLifecycle(),
+		options:   opts,
+		schedule:  schedule,
+	}
+	if opts.RateLimit != nil {
+		h.limiter = newRateLimiter(*opts.RateLimit)
+	}
+	if opts.Incidents != nil {
+		if h.incidents, err = newIncidentTracker(*opts.Incidents); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Heartbeat != nil {
+		if h.heartbeat, err = newHeartbeat(*opts.Heartbeat, *h); err != nil {
+			return nil, err
+		}
+		h.heartbeat.start()
+	}
+	return h, nil
 }
 
 // Enabled determines whether or not the given level is enabled in this handler.
+//
+// Levels affected by an active schedule window whose action is ScheduleActionRaiseLevel are not enabled. No levels are
+// enabled if the context was created using WithSuppress().
 func (h slackHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.options.Level.Level()
+	if level < h.options.Level.Level() || GetMessageOverridesFromContext(ctx).Suppress {
+		return false
+	}
+	w, _, _, ok := h.schedule.match(time.Now(), level)
+	return !ok || w.window.Action != ScheduleActionRaiseLevel
+}
+
+// Flush waits for any pending records to be delivered without shutting down the handler.
+//
+// Any delivery errors which occurred since the last call to Flush() or Shutdown() are joined together and returned.
+// If the context is done before all pending records have been delivered, the context's error is included as well.
+func (h slackHandler) Flush(ctx context.Context) error {
+	pending, errs := h.lifecycle.drain(ctx, true)
+	if pending > 0 {
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
 }
 
 // Handle actually handles posting the record to the Slack webhook.
 //
+// The record's attributes are qualified by the full path of groups added to the handler using WithGroup(), following
+// the same semantics as log/slog. Empty attributes and groups without any attributes are removed.
+//
 // Any attributes duplicated between the handler and record, including within groups, are automaticlaly removed.
 // If a duplicate is encountered, the last value found will be used for the attribute's value.
+//
+// If a schedule window is active, the record is dropped, redirected or held according to the window's action. Records
+// which exceed the RateLimit option are dropped.
+//
+// Any overrides added to the context using WithChannel(), WithThread() or WithSuppress() are applied to the message
+// after it has been formatted, taking priority over the channel and thread set by the formatter. Records belonging to
+// an incident are always posted to the incident's channel and thread.
+//
+// If the handler has already been shut down, ErrHandlerShutdown is returned.
 func (h *slackHandler) Handle(ctx context.Context, r slog.Record) error {
+	if err := h.lifecycle.begin(); err != nil {
+		return err
+	}
+	h.lifecycle.count(r.Level)
+	if h.lifecycle.isPaused() || GetMessageOverridesFromContext(ctx).Suppress {
+		h.lifecycle.dropped()
+		h.lifecycle.end(nil)
+		return nil
+	}
+	webhookURL := h.options.WebhookURL
+	if w, start, end, ok := h.schedule.match(time.Now(), r.Level); ok {
+		switch w.window.Action {
+		case ScheduleActionHold:
+			// once the handler is shutting down, records are no longer held but posted as normal
+			if h.schedule.hold(w, start, end, r, h.postHeldSummary) {
+				h.lifecycle.heldRecord()
+				h.lifecycle.end(nil)
+				return nil
+			}
+		case ScheduleActionRaiseLevel:
+			h.lifecycle.dropped()
+			h.lifecycle.end(nil)
+			return nil
+		case ScheduleActionRedirect:
+			webhookURL = w.window.WebhookURL
+		}
+	}
+	if !h.limiter.allow(time.Now()) {
+		h.lifecycle.dropped()
+		h.lifecycle.end(nil)
+		return nil
+	}
+	extracted := h.extractContextAttrs(ctx)
 	handlerCtx := h.options.AddToContext(ctx)
 	if !h.options.EnableAsync {
-		return h.handle(handlerCtx, r)
+		err := h.handle(handlerCtx, r, extracted, webhookURL)
+		h.lifecycle.end(nil)
+		return err
 	}
 
-	future := async.Exec(func() any {
-		return h.handle(handlerCtx, r)
-	})
-	h.futures = append(h.futures, future)
+	r = r.Clone()
+	go func() {
+		h.lifecycle.end(h.handle(handlerCtx, r, extracted, webhookURL))
+	}()
 	return nil
 }
 
-// Shutdown is responsible for cleaning up resources used by the handler.
-func (h slackHandler) Shutdown(continueOnError bool) error {
-	for _, f := range h.futures {
-		if f != nil {
-			f.Await()
-		}
+// Level returns the current minimum log level written by the handler.
+func (h slackHandler) Level() slog.Level {
+	return h.options.Level.Level()
+}
+
+// Pause stops the handler from posting messages to Slack until Resume() is called.
+//
+// Records passed to the handler while it is paused are dropped rather than queued. Pausing applies to this handler
+// and every handler created from it or its parent using WithAttrs() or WithGroup().
+func (h slackHandler) Pause() {
+	h.lifecycle.setPaused(true)
+}
+
+// Paused determines whether or not the handler is currently paused.
+func (h slackHandler) Paused() bool {
+	return h.lifecycle.isPaused()
+}
+
+// Resume resumes posting messages to Slack after a call to Pause().
+func (h slackHandler) Resume() {
+	h.lifecycle.setPaused(false)
+}
+
+// SetLevel changes the minimum log level written by the handler.
+//
+// The Level option must be a *slog.LevelVar, otherwise ErrLevelNotAdjustable is returned. The change applies to every
+// handler sharing the same *slog.LevelVar.
+func (h slackHandler) SetLevel(level slog.Level) error {
+	lv, ok := h.options.Level.(*slog.LevelVar)
+	if !ok {
+		return ErrLevelNotAdjustable
 	}
+	lv.Set(level)
 	return nil
 }
 
+// Shutdown stops the handler from accepting new records and waits for any pending records to be delivered.
+//
+// The handler waits for at most the ShutdownTimeout option's duration. If continueOnError is false, the handler stops
+// waiting as soon as a delivery error is encountered.
+//
+// See ShutdownContext() for details on the error returned.
+func (h slackHandler) Shutdown(continueOnError bool) error {
+	ctx := context.Background()
+	if h.options.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.options.ShutdownTimeout)
+		defer cancel()
+	}
+	return h.shutdown(ctx, continueOnError)
+}
+
+// ShutdownContext stops the handler from accepting new records and waits for any pending records to be delivered or
+// for the context to be done, whichever happens first.
+//
+// The shutdown applies to this handler and every handler created from it or its parent using WithAttrs() or
+// WithGroup(). Any subsequent calls to Handle() return ErrHandlerShutdown.
+//
+// Summaries of any records held by active schedule windows are posted and waited for along with any pending records.
+// If the heartbeat is enabled, it is stopped and a final "stopped" message is posted once pending records have been
+// delivered, using its own deadline (see HeartbeatOptions.StopTimeout).
+//
+// Every delivery error which occurred since the last call to Flush() is joined together and returned. If any records
+// are still pending when the context is done, their deliveries are cancelled and an *AbandonedRecordsError containing
+// the number of abandoned records is included in the returned error.
+func (h slackHandler) ShutdownContext(ctx context.Context) error {
+	return h.shutdown(ctx, true)
+}
+
+// Stats returns the delivery statistics for the handler and every handler created from it or its parent using
+// WithAttrs() or WithGroup().
+func (h slackHandler) Stats() HandlerStats {
+	return h.lifecycle.snapshot()
+}
+
 // WithAttrs creates a new handler from the existing one adding the given attributes to it.
+//
+// The attributes are qualified by any groups previously added to the handler using WithGroup().
 func (h slackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	newHandler := &slackHandler{
-		attrs:   h.attrs,
-		futures: h.futures,
-		groups:  h.groups,
-		options: h.options,
-	}
-	if h.activeGroup == "" {
-		newHandler.attrs = append(newHandler.attrs, attrs...)
-	} else {
-		newHandler.attrs = append(newHandler.attrs, slog.Group(h.activeGroup, generic.AnySlice(attrs)...))
-		newHandler.activeGroup = h.activeGroup
+	if len(attrs) == 0 {
+		return &h
+	}
+	return &slackHandler{
+		goas:      withGroupOrAttrs(h.goas, groupOrAttrs{attrs: attrs}),
+		heartbeat: h.heartbeat,
+		incidents: h.incidents,
+		lifecycle: h.lifecycle,
+		limiter:   h.limiter,
+		options:   h.options,
+		schedule:  h.schedule,
 	}
-	return newHandler
 }
 
 // WithGroup creates a new handler from the existing one adding the given group to it.
+//
+// If the name is empty, the handler is returned unchanged.
 func (h slackHandler) WithGroup(name string) slog.Handler {
-	newHandler := &slackHandler{
-		attrs:   h.attrs,
-		futures: h.futures,
-		groups:  h.groups,
-		options: h.options,
+	if name == "" {
+		return &h
 	}
-	if name != "" {
-		newHandler.groups = append(newHandler.groups, name)
-		newHandler.activeGroup = name
+	return &slackHandler{
+		goas:      withGroupOrAttrs(h.goas, groupOrAttrs{group: name}),
+		heartbeat: h.heartbeat,
+		incidents: h.incidents,
+		lifecycle: h.lifecycle,
+		limiter:   h.limiter,
+		options:   h.options,
+		schedule:  h.schedule,
+	}
+}
+
+// shutdown posts any held records, stops the heartbeat and shuts down the lifecycle.
+func (h slackHandler) shutdown(ctx context.Context, continueOnError bool) error {
+	h.releaseHeld()
+	if h.heartbeat == nil {
+		return h.lifecycle.shutdown(ctx, continueOnError)
 	}
-	return newHandler
+
+	first := h.heartbeat.stop()
+	err := h.lifecycle.shutdown(ctx, continueOnError)
+	if !first {
+		return err
+	}
+	return errors.Join(err, h.heartbeat.stopped(ctx))
 }
 
-// handle is responsible for actually posting the message using the Slack webhook.
-func (h slackHandler) handle(ctx context.Context, r slog.Record) error {
-	attrs := slogx.ConsolidateAttrs(h.attrs, h.activeGroup, r)
+// handle is responsible for actually posting the message using the given Slack webhook.
+//
+// Any attributes extracted from the context are added to the top level of the record's attributes.
+func (h slackHandler) handle(ctx context.Context, r slog.Record, extracted []slog.Attr, webhookURL Secret) error {
+	attrs := buildAttrs(h.goas, r, extracted...)
+	ctx = withGroupPath(ctx, h.goas)
 
 	// format the output into a Slack message
 	var message *slack.WebhookMessage
@@ -184,9 +464,83 @@ func (h slackHandler) handle(ctx context.Context, r slog.Record) error {
 		message, err = f.FormatRecord(ctx, r.Time, slogx.Level(r.Level), r.PC, r.Message, attrs)
 	}
 	if err != nil {
+		h.lifecycle.delivered(err)
 		return err
 	}
+	if message == nil {
+		h.lifecycle.dropped()
+		return nil
+	}
 
 	// send the message to Slack
-	return slack.PostWebhookCustomHTTP(h.options.WebhookURL, h.options.HTTPClient, message)
+	postCtx, cancel := h.lifecycle.bind(context.WithoutCancel(ctx))
+	defer cancel()
+	if h.options.DeliveryObserver != nil {
+		postCtx = h.options.DeliveryObserver.StartDelivery(postCtx, r.Level)
+	}
+	start := time.Now()
+	if id, state := h.incidentOf(attrs, GetGroupPathFromContext(ctx)); id != "" {
+		err = h.incidents.deliver(postCtx, h.incidentFormatter(), h.options.HTTPClient, r, id, state, message)
+		err = scrubError(err, h.incidents.options.Token)
+	} else {
+		GetMessageOverridesFromContext(ctx).apply(message)
+		converted := h.options.Compatibility.convert(h.levelColor(r.Level), message)
+		err = h.options.Retry.do(postCtx, func() error {
+			return slack.PostWebhookCustomHTTPContext(postCtx, webhookURL.Value(), h.options.HTTPClient, converted)
+		})
+		err = scrubError(err, webhookURL, h.options.WebhookURL)
+	}
+	h.lifecycle.delivered(err)
+	if h.options.DeliveryObserver != nil {
+		h.options.DeliveryObserver.EndDelivery(postCtx, r.Level, time.Since(start), err)
+	}
+	return err
+}
+
+// incidentFormatter returns the formatter used to format the parent messages of incidents.
+func (h slackHandler) incidentFormatter() IncidentFormatter {
+	if f, ok := h.options.RecordFormatter.(IncidentFormatter); ok {
+		return f
+	}
+	return DefaultSlackMessageFormatter()
+}
+
+// levelColor returns the attachment color used for the level when posting to Slack-compatible targets.
+//
+// The color is taken from the level registry of the handler's RecordFormatter, if it has one, or the default level
+// registry otherwise.
+func (h slackHandler) levelColor(level slog.Level) string {
+	if f, ok := h.options.RecordFormatter.(levelStyler); ok {
+		return f.levelStyle(level).Color
+	}
+	return defaultLevelRegistry.Lookup(level).Color
+}
+
+// levelRoutes returns the default channel and mentions of each range of levels.
+//
+// The routes are taken from the level registry of the handler's RecordFormatter, if it has one, or the default level
+// registry otherwise.
+func (h slackHandler) levelRoutes() []LevelRoute {
+	if f, ok := h.options.RecordFormatter.(levelStyler); ok {
+		return f.levelRegistry().Routes()
+	}
+	return defaultLevelRegistry.Routes()
+}
+
+// incidentOf returns the ID and state of the incident the record's attributes belong to.
+//
+// The attributes are looked up relative to the given path of groups added to the handler. If incidents are not being
+// tracked or the record does not belong to an incident, the ID is empty.
+func (h slackHandler) incidentOf(attrs []slog.Attr, groups []string) (string, IncidentState) {
+	if h.incidents == nil {
+		return "", ""
+	}
+	return h.incidents.lookup(attrs, groups)
+}
+
+// newLevelVar creates a new *slog.LevelVar set to the given level.
+func newLevelVar(level slog.Level) *slog.LevelVar {
+	lv := &slog.LevelVar{}
+	lv.Set(level)
+	return lv
 }
@@ -1,18 +1,21 @@
 package slogxslack_test
 
-// TODO: implement testing and benchmarks
-
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
 	"go.innotegrity.dev/errorx"
 	"go.innotegrity.dev/slogx"
 	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
 )
 
 func TestSlack1(t *testing.T) {
@@ -21,18 +24,24 @@ func TestSlack1(t *testing.T) {
 	slackFormatterOptions.ApplicationIconURL = "https://d1nhio0ox7pgb.cloudfront.net/_img/v_collection_png/512x512/shadow/log.png"
 	slackFormatterOptions.IncludeSource = true
 	slackFormatter := slogxslack.NewSlackMessageFormatter(slackFormatterOptions)
+	webhookURL := os.Getenv("SLOGX_SLACK_WEBHOOK_URL")
+	var server *slacktest.Server
+	if webhookURL == "" {
+		server = slacktest.NewServer()
+		defer server.Close()
+		webhookURL = server.WebhookURL()
+	}
 	slackHandler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
 		EnableAsync:     true,
 		Level:           slogx.LevelTrace,
 		RecordFormatter: slackFormatter,
-		WebhookURL:      os.Getenv("SLOGX_SLACK_WEBHOOK_URL"),
+		WebhookURL:      slogxslack.NewSecret(webhookURL),
 	})
 	if err != nil {
 		t.Errorf("failed to create Slack Handler: %s", err.Error())
 		return
 	}
 	logger := slogx.Wrap(slog.New(slackHandler))
-	defer logger.Shutdown(true)
 
 	logger.Trace("this is a trace message")
 	logger.Debug("this is a debug message")
@@ -98,6 +107,147 @@ func TestSlack1(t *testing.T) {
 		),
 	)
 
+	if err := slackHandler.Shutdown(true); err != nil {
+		t.Errorf("failed to deliver messages: %s", err.Error())
+	}
+	if server == nil {
+		return
+	}
+	messages := server.Messages()
+	if len(messages) != 10 {
+		t.Fatalf("expected 10 messages, got %d", len(messages))
+	}
+	// async messages may arrive in any order
+	var errorMessage slacktest.Message
+	for _, m := range messages {
+		slacktest.AssertContainsText(t, m, "slogx")
+		if m.ContainsText("this is an error message") {
+			errorMessage = m
+		}
+	}
+	slacktest.AssertContainsText(t, errorMessage, "*root_key*: `1`")
+	slacktest.AssertContainsText(t, errorMessage, "*group1.nested.logger_name*: `frodo`")
+}
+
+func TestShutdownContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		EnableAsync: true,
+		WebhookURL:  slogxslack.NewSecret(server.URL),
+	})
+	if err != nil {
+		t.Fatalf("failed to create Slack handler: %s", err.Error())
+	}
+	logger := slog.New(handler).With(slog.String("key", "value"))
+	logger.Info("first message")
+	logger.WithGroup("group").Info("second message")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = handler.ShutdownContext(ctx)
+	var abandonedErr *slogxslack.AbandonedRecordsError
+	if !errors.As(err, &abandonedErr) {
+		t.Fatalf("expected an AbandonedRecordsError, got: %v", err)
+	}
+	if abandonedErr.Count != 2 {
+		t.Errorf("expected 2 abandoned records, got %d", abandonedErr.Count)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error to wrap context.DeadlineExceeded, got: %v", err)
+	}
+
+	derived := logger.WithGroup("after").Handler()
+	if err := derived.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "late", 0)); !errors.Is(err,
+		slogxslack.ErrHandlerShutdown) {
+		t.Errorf("expected ErrHandlerShutdown from a derived handler, got: %v", err)
+	}
+}
+
+func TestFlushReturnsDeliveryErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		EnableAsync: true,
+		WebhookURL:  slogxslack.NewSecret(server.URL),
+	})
+	if err != nil {
+		t.Fatalf("failed to create Slack handler: %s", err.Error())
+	}
+	logger := slog.New(handler)
+	logger.Info("first message")
+	logger.Info("second message")
+
+	err = handler.Flush(context.Background())
+	if err == nil {
+		t.Fatal("expected delivery errors from Flush")
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+		t.Errorf("expected 2 joined delivery errors, got: %v", err)
+	}
+	if err := handler.Shutdown(true); err != nil {
+		t.Errorf("expected no errors after flushing, got: %v", err)
+	}
+}
+
+func TestSlogtest(t *testing.T) {
+	err := slacktest.TestHandler(func(webhookURL string, f slogxslack.SlackMessageFormatter) (slog.Handler, error) {
+		return slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+			Level:           slog.LevelDebug,
+			RecordFormatter: f,
+			WebhookURL:      slogxslack.NewSecret(webhookURL),
+		})
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGroupPaths(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+	f := slacktest.NewRecordingFormatter(nil)
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		RecordFormatter: f,
+		WebhookURL:      slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create Slack handler: %s", err.Error())
+	}
+
+	parent := slog.New(handler).WithGroup("a").WithGroup("b").With(slog.String("x", "1"))
+	first := parent.With(slog.String("y", "first"))
+	second := parent.With(slog.String("y", "second"))
+	first.Info("first message", slog.Int("z", 1))
+	second.WithGroup("empty").Info("second message")
+
+	results := f.Records()
+	expected := []map[string]any{
+		{"a": map[string]any{"b": map[string]any{"x": "1", "y": "first", "z": int64(1)}}},
+		{"a": map[string]any{"b": map[string]any{"x": "1", "y": "second"}}},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(results))
+	}
+	for i, r := range results {
+		delete(r, slog.TimeKey)
+		delete(r, slog.LevelKey)
+		delete(r, slog.MessageKey)
+		if !reflect.DeepEqual(r, expected[i]) {
+			t.Errorf("record %d: expected %v, got %v", i, expected[i], r)
+		}
+	}
 }
 
 type User struct {
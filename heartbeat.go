@@ -0,0 +1,239 @@
+package slogxslack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+)
+
+const (
+	// DefaultHeartbeatInterval is the default amount of time between heartbeat messages.
+	DefaultHeartbeatInterval = 5 * time.Minute
+
+	// DefaultHeartbeatStopTimeout is the default maximum amount of time to wait for the final "stopped" message to be
+	// posted.
+	DefaultHeartbeatStopTimeout = 5 * time.Second
+)
+
+// HeartbeatOptions holds the options for the handler's heartbeat.
+//
+// The heartbeat periodically posts a status message showing the application's uptime, the number of records handled
+// by level since the last heartbeat and the number of delivery failures. This allows a quiet channel to be told apart
+// from an application which is no longer running. When the handler is shut down, a final "stopped" message is posted.
+type HeartbeatOptions struct {
+	// APIURL is the base URL of the Slack Web API used when Token is set.
+	//
+	// If empty, slack.APIURL is used.
+	APIURL string
+
+	// Channel is the ID of the channel to post the heartbeat message to when Token is set.
+	//
+	// This is a required option if Token is set.
+	Channel string
+
+	// Interval is the amount of time between heartbeat messages.
+	//
+	// If zero, DefaultHeartbeatInterval is used.
+	Interval time.Duration
+
+	// Name is the name of the application shown in the heartbeat message.
+	//
+	// If empty, the name of the running executable is used.
+	Name string
+
+	// StopTimeout is the maximum amount of time to wait for the final "stopped" message to be posted.
+	//
+	// The message is posted once the handler has finished waiting for pending records, so it is given its own
+	// deadline rather than sharing the shutdown's deadline, which may already have been used up. If zero,
+	// DefaultHeartbeatStopTimeout is used.
+	StopTimeout time.Duration
+
+	// Token is a Slack bot token used to post the heartbeat message through the Web API.
+	//
+	// When a token is supplied, a single status message is posted and then updated in place at every interval. If
+	// empty, a new message is posted through the handler's webhook at every interval instead.
+	Token Secret
+}
+
+// heartbeat periodically posts a status message on behalf of a handler.
+type heartbeat struct {
+	cancel     context.CancelFunc
+	channelID  string
+	done       chan struct{}
+	handler    slackHandler
+	lastFailed uint64
+	mu         sync.Mutex
+	options    HeartbeatOptions
+	started    time.Time
+	stopOnce   sync.Once
+	timestamp  string
+}
+
+// newHeartbeat validates the options and creates a new heartbeat for the handler.
+//
+// The heartbeat is not started until start() is called.
+func newHeartbeat(opts HeartbeatOptions, h slackHandler) (*heartbeat, error) {
+	if !opts.Token.IsZero() && opts.Channel == "" {
+		return nil, errors.New("heartbeat channel is required when a token is supplied")
+	}
+	if opts.APIURL == "" {
+		opts.APIURL = slack.APIURL
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultHeartbeatInterval
+	}
+	if opts.Name == "" {
+		opts.Name = filepath.Base(os.Args[0])
+	}
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = DefaultHeartbeatStopTimeout
+	}
+	return &heartbeat{
+		done:    make(chan struct{}),
+		handler: h,
+		options: opts,
+		started: time.Now(),
+	}, nil
+}
+
+// start starts posting heartbeat messages in a separate goroutine, beginning immediately.
+func (hb *heartbeat) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	hb.cancel = cancel
+	go func() {
+		defer close(hb.done)
+		ticker := time.NewTicker(hb.options.Interval)
+		defer ticker.Stop()
+		for {
+			hb.beat(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// stop stops posting heartbeat messages and waits for any heartbeat in progress to finish.
+//
+// The function returns true only for the call which actually stopped the heartbeat.
+func (hb *heartbeat) stop() bool {
+	stopped := false
+	hb.stopOnce.Do(func() {
+		stopped = true
+		hb.cancel()
+	})
+	<-hb.done
+	return stopped
+}
+
+// beat posts or updates the heartbeat message.
+//
+// Any delivery error is counted in the handler's statistics and reported through the handler's Flush() and Shutdown()
+// functions, unless the heartbeat was stopped while the message was being posted.
+func (hb *heartbeat) beat(ctx context.Context) {
+	if err := hb.handler.lifecycle.begin(); err != nil {
+		return
+	}
+	text := fmt.Sprintf(":large_green_circle: *%s* is running", hb.options.Name)
+	err := hb.post(ctx, text, true)
+	if ctx.Err() != nil {
+		err = nil
+	} else {
+		hb.handler.lifecycle.delivered(err)
+	}
+	hb.handler.lifecycle.end(err)
+}
+
+// stopped posts the final message indicating that the application has stopped.
+//
+// The message is posted using its own deadline of StopTimeout, ignoring any deadline or cancellation of the given
+// context. Any delivery error is counted in the handler's statistics and returned.
+func (hb *heartbeat) stopped(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), hb.options.StopTimeout)
+	defer cancel()
+	text := fmt.Sprintf(":black_circle: *%s* has stopped", hb.options.Name)
+	err := hb.post(ctx, text, false)
+	hb.handler.lifecycle.delivered(err)
+	return err
+}
+
+// post posts the heartbeat message with the given text, along with the current statistics.
+//
+// If update is true and a token was supplied, the previously posted message is updated instead.
+func (hb *heartbeat) post(ctx context.Context, text string, update bool) error {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	// build the message
+	counts, stats := hb.handler.lifecycle.takeLevelCounts(), hb.handler.lifecycle.snapshot()
+	failed := stats.Failed - hb.lastFailed
+	hb.lastFailed = stats.Failed
+	lines := []string{
+		text,
+		fmt.Sprintf("*Uptime:* %s", time.Since(hb.started).Round(time.Second)),
+		fmt.Sprintf("*Records since last heartbeat:* %s", formatLevelCounts(counts)),
+		fmt.Sprintf("*Delivery failures since last heartbeat:* %d", failed),
+	}
+	content := strings.Join(lines, "\n")
+	blocks := slack.Blocks{
+		BlockSet: []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, content, false, false), nil, nil),
+		},
+	}
+
+	// post it through the webhook if there is no token
+	if hb.options.Token.IsZero() {
+		message := hb.handler.options.Compatibility.convert(hb.handler.levelColor(slog.LevelInfo),
+			&slack.WebhookMessage{Blocks: &blocks, Text: text})
+		err := hb.handler.options.Retry.do(ctx, func() error {
+			return slack.PostWebhookCustomHTTPContext(ctx, hb.handler.options.WebhookURL.Value(),
+				hb.handler.options.HTTPClient, message)
+		})
+		return scrubError(err, hb.handler.options.WebhookURL)
+	}
+
+	// otherwise post or update it through the Web API
+	client := slack.New(hb.options.Token.Value(), slack.OptionAPIURL(hb.options.APIURL),
+		slack.OptionHTTPClient(hb.handler.options.HTTPClient))
+	msgOpts := []slack.MsgOption{slack.MsgOptionText(text, false), slack.MsgOptionBlocks(blocks.BlockSet...)}
+	var err error
+	if update && hb.timestamp != "" {
+		_, _, _, err = client.UpdateMessageContext(ctx, hb.channelID, hb.timestamp, msgOpts...)
+	} else {
+		var channelID, timestamp string
+		channelID, timestamp, err = client.PostMessageContext(ctx, hb.options.Channel, msgOpts...)
+		if err == nil && update {
+			hb.channelID, hb.timestamp = channelID, timestamp
+		}
+	}
+	return scrubError(err, hb.options.Token)
+}
+
+// formatLevelCounts formats the number of records handled at each level, from highest to lowest level.
+func formatLevelCounts(counts map[slog.Level]uint64) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	levels := make([]slog.Level, 0, len(counts))
+	for l := range counts {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] > levels[j] })
+	parts := make([]string, 0, len(levels))
+	for _, l := range levels {
+		parts = append(parts, fmt.Sprintf("%s: %d", slogx.Level(l).String(), counts[l]))
+	}
+	return strings.Join(parts, ", ")
+}
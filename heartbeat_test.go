@@ -0,0 +1,112 @@
+package slogxslack_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestHeartbeatWebhook(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Heartbeat: &slogxslack.HeartbeatOptions{
+			Interval: 50 * time.Millisecond,
+			Name:     "heartbeat-test",
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	slog.New(handler).Error("counted")
+
+	server.WaitForMessages(t, 4, 5*time.Second)
+	if err := handler.ShutdownContext(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %s", err.Error())
+	}
+
+	messages := server.Messages()
+	last := messages[len(messages)-1]
+	slacktest.AssertContainsText(t, last, "*heartbeat-test* has stopped")
+	running, counted := false, false
+	for _, m := range messages {
+		if m.ContainsText("*heartbeat-test* is running") {
+			running = true
+			counted = counted || m.ContainsText("ERROR: 1")
+		}
+	}
+	if !running || !counted {
+		t.Error("expected a heartbeat to report the error record")
+	}
+}
+
+func TestHeartbeatUpdatesMessage(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Heartbeat: &slogxslack.HeartbeatOptions{
+			APIURL:   server.APIURL(),
+			Channel:  "C0000000000",
+			Interval: 50 * time.Millisecond,
+			Token:    slogxslack.NewSecret("xoxb-test"),
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	messages := server.WaitForMessages(t, 3, 5*time.Second)
+	if err := handler.ShutdownContext(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %s", err.Error())
+	}
+
+	if messages[0].Endpoint != slacktest.EndpointPostMessage {
+		t.Errorf("expected the first heartbeat to be posted, got %s", messages[0].Endpoint)
+	}
+	for _, m := range messages[1:] {
+		if m.Endpoint != slacktest.EndpointUpdateMessage || m.Timestamp != messages[0].Timestamp {
+			t.Errorf("expected later heartbeats to update the first message, got %s for %s", m.Endpoint,
+				m.Timestamp)
+		}
+	}
+	messages = server.Messages()
+	if last := messages[len(messages)-1]; last.Endpoint != slacktest.EndpointPostMessage {
+		t.Errorf("expected the stopped message to be posted as a new message, got %s", last.Endpoint)
+	}
+}
+
+func TestHeartbeatFailuresAndStopDeadline(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+	server.Fail(1, http.StatusInternalServerError)
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Heartbeat: &slogxslack.HeartbeatOptions{
+			Interval: 50 * time.Millisecond,
+			Name:     "heartbeat-test",
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	server.WaitForMessages(t, 1, 5*time.Second)
+	if stats := handler.Stats(); stats.Failed != 1 {
+		t.Errorf("expected the failed heartbeat to be counted, got %+v", stats)
+	}
+
+	// the stopped message is still posted when the shutdown's deadline has already passed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = handler.ShutdownContext(ctx)
+	messages := server.Messages()
+	slacktest.AssertContainsText(t, messages[len(messages)-1], "*heartbeat-test* has stopped")
+}
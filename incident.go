@@ -0,0 +1,414 @@
+package slogxslack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+)
+
+const (
+	// DefaultIncidentKeyAttr is the default attribute used to identify the incident a record belongs to.
+	DefaultIncidentKeyAttr = "incident"
+
+	// DefaultIncidentStateAttr is the default attribute used to set the state of an incident.
+	DefaultIncidentStateAttr = "incident_state"
+
+	// DefaultIncidentTTL is the default amount of time an incident is tracked after its last record.
+	DefaultIncidentTTL = 24 * time.Hour
+
+	// IncidentOpenColor is the color of the parent message of an open incident.
+	IncidentOpenColor = "#e01e5a"
+
+	// IncidentResolvedColor is the color of the parent message of a resolved incident.
+	IncidentResolvedColor = "#2eb67d"
+)
+
+// IncidentState is the state of an incident.
+type IncidentState string
+
+const (
+	// IncidentStateOpen indicates the incident is still ongoing.
+	IncidentStateOpen IncidentState = "open"
+
+	// IncidentStateResolved indicates the incident has been resolved.
+	IncidentStateResolved IncidentState = "resolved"
+)
+
+// Incident holds the details of an incident tracked by the handler.
+type Incident struct {
+	// ID uniquely identifies the incident.
+	ID string
+
+	// Level is the highest level of any record belonging to the incident.
+	Level slogx.Level
+
+	// Message is the message of the record which opened the incident.
+	Message string
+
+	// OpenedAt is the time of the record which opened the incident.
+	OpenedAt time.Time
+
+	// ResolvedAt is the time of the record which resolved the incident.
+	//
+	// This is zero if the incident is still open.
+	ResolvedAt time.Time
+
+	// State is the current state of the incident.
+	State IncidentState
+
+	// Updates is the number of records belonging to the incident, not including the one which opened it.
+	Updates int
+}
+
+// IncidentFormatter describes the interface a formatter which outputs the parent message of an incident must
+// implement.
+//
+// If the handler's RecordFormatter does not implement this interface, the default formatter's layouts are used.
+type IncidentFormatter interface {
+	// FormatIncident should format the parent message of the incident in its current state.
+	FormatIncident(context.Context, Incident) (*slack.WebhookMessage, error)
+}
+
+// IncidentOptions holds the options for tracking incidents.
+//
+// Records with the KeyAttr attribute are grouped into a single incident. The first record opens the incident by
+// posting a parent message, which is then followed by a thread reply containing each record belonging to the incident.
+// When a record's StateAttr attribute is "resolved", the parent message is edited to show the incident has been
+// resolved and, once the edit succeeds, the incident is no longer tracked.
+//
+// Because threads and edits require the Slack Web API, incident records are posted using Token rather than the
+// handler's webhook.
+type IncidentOptions struct {
+	// APIURL is the base URL of the Slack Web API.
+	//
+	// If empty, slack.APIURL is used.
+	APIURL string
+
+	// Channel is the ID of the channel to post incident messages to.
+	//
+	// This is a required option.
+	Channel string
+
+	// KeyAttr is the name of the attribute which holds the ID of the incident a record belongs to.
+	//
+	// The attribute is looked up at the top level of the record's attributes and within the groups added to the
+	// handler using WithGroup(), so its key is relative to the handler's group path. If empty, DefaultIncidentKeyAttr
+	// is used.
+	KeyAttr string
+
+	// StateAttr is the name of the attribute which holds the state of the incident ("open" or "resolved").
+	//
+	// The attribute is looked up in the same way as KeyAttr. Records without the attribute are treated as updates to an
+	// open incident. If empty, DefaultIncidentStateAttr is used.
+	StateAttr string
+
+	// TTL is the amount of time an incident is tracked after its last record.
+	//
+	// A record for an incident which is no longer tracked opens a new incident. If zero, DefaultIncidentTTL is used.
+	TTL time.Duration
+
+	// Token is the Slack bot token used to post incident messages.
+	//
+	// This is a required option.
+	Token Secret
+}
+
+// trackedIncident is an incident being tracked by the handler.
+//
+// Records for the same incident are delivered one at a time while holding the incident's lock.
+type trackedIncident struct {
+	channelID string
+	closed    bool
+	incident  Incident
+	lastSeen  time.Time
+	mu        sync.Mutex
+	opened    bool
+	timestamp string
+}
+
+// incidentTracker tracks the open incidents for a handler and every handler derived from it.
+type incidentTracker struct {
+	incidents map[string]*trackedIncident
+	mu        sync.Mutex
+	options   IncidentOptions
+}
+
+// newIncidentTracker validates the options and creates a new tracker.
+func newIncidentTracker(opts IncidentOptions) (*incidentTracker, error) {
+	if opts.Channel == "" {
+		return nil, errors.New("incident channel is required and cannot be empty")
+	}
+	if opts.Token.IsZero() {
+		return nil, errors.New("incident token is required and cannot be empty")
+	}
+	if opts.APIURL == "" {
+		opts.APIURL = slack.APIURL
+	}
+	if opts.KeyAttr == "" {
+		opts.KeyAttr = DefaultIncidentKeyAttr
+	}
+	if opts.StateAttr == "" {
+		opts.StateAttr = DefaultIncidentStateAttr
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultIncidentTTL
+	}
+	return &incidentTracker{
+		incidents: map[string]*trackedIncident{},
+		options:   opts,
+	}, nil
+}
+
+// lookup returns the ID and state of the incident the attributes belong to, if any.
+//
+// The attributes are looked up at the top level and within each of the given groups added to the handler, with those
+// nested deepest taking precedence.
+func (t *incidentTracker) lookup(attrs []slog.Attr, groups []string) (string, IncidentState) {
+	id, state := "", IncidentState("")
+	for {
+		var nested []slog.Attr
+		for _, a := range attrs {
+			value := a.Value.Resolve()
+			switch {
+			case a.Key == t.options.KeyAttr:
+				id = value.String()
+			case a.Key == t.options.StateAttr:
+				state = IncidentState(strings.ToLower(value.String()))
+			case len(groups) > 0 && a.Key == groups[0] && value.Kind() == slog.KindGroup:
+				nested = value.Group()
+			}
+		}
+		if nested == nil {
+			return id, state
+		}
+		attrs, groups = nested, groups[1:]
+	}
+}
+
+// deliver posts the record's message as part of the given incident.
+//
+// If the incident is not being tracked, its parent message is posted first. The parent message is then edited to
+// reflect the incident's current state. A resolved incident is only forgotten once its parent message shows it has
+// been resolved, so that resolving it again after a failure edits the same parent message.
+//
+// Records for the same incident are delivered one at a time so that concurrent records for a new incident only open it
+// once, while records for different incidents are delivered concurrently.
+func (t *incidentTracker) deliver(ctx context.Context, f IncidentFormatter, httpClient *http.Client,
+	r slog.Record, id string, state IncidentState, message *slack.WebhookMessage) error {
+
+	client := slack.New(t.options.Token.Value(), slack.OptionAPIURL(t.options.APIURL),
+		slack.OptionHTTPClient(httpClient))
+	for {
+		tracked := t.claim(id)
+		tracked.mu.Lock()
+		if tracked.closed {
+			// the incident was resolved, or could not be opened, while waiting for it so track it again
+			tracked.mu.Unlock()
+			continue
+		}
+		err := t.deliverTo(ctx, client, f, tracked, r, id, state, message)
+		tracked.mu.Unlock()
+		return err
+	}
+}
+
+// claim returns the incident with the given ID, tracking a new incident whose parent message has not been posted yet
+// if it is not being tracked.
+//
+// Any incidents which have not been seen within the TTL are no longer tracked.
+func (t *incidentTracker) claim(id string) *trackedIncident {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	for key, tracked := range t.incidents {
+		if now.Sub(tracked.lastSeen) > t.options.TTL {
+			delete(t.incidents, key)
+		}
+	}
+	tracked, ok := t.incidents[id]
+	if !ok {
+		tracked = &trackedIncident{}
+		t.incidents[id] = tracked
+	}
+	tracked.lastSeen = now
+	return tracked
+}
+
+// deliverTo posts the record's message as part of the given incident, opening it if its parent message has not been
+// posted yet.
+//
+// The caller must hold the incident's lock.
+func (t *incidentTracker) deliverTo(ctx context.Context, client *slack.Client, f IncidentFormatter,
+	tracked *trackedIncident, r slog.Record, id string, state IncidentState, message *slack.WebhookMessage) error {
+
+	// open the incident
+	if !tracked.opened {
+		tracked.incident = Incident{
+			ID:       id,
+			Level:    slogx.Level(r.Level),
+			Message:  r.Message,
+			OpenedAt: r.Time,
+			State:    IncidentStateOpen,
+		}
+		if state == IncidentStateResolved {
+			tracked.incident.ResolvedAt = r.Time
+			tracked.incident.State = IncidentStateResolved
+		}
+		parent, err := f.FormatIncident(ctx, tracked.incident)
+		if err != nil {
+			t.forget(id, tracked)
+			return err
+		}
+		tracked.channelID, tracked.timestamp, err = client.PostMessageContext(ctx, t.options.Channel,
+			webhookMessageOptions(parent)...)
+		if err != nil {
+			t.forget(id, tracked)
+			return err
+		}
+		tracked.opened = true
+		msgOpts := append(webhookMessageOptions(message), slack.MsgOptionTS(tracked.timestamp))
+		if _, _, err = client.PostMessageContext(ctx, tracked.channelID, msgOpts...); err != nil {
+			return err
+		}
+		if state == IncidentStateResolved {
+			t.forget(id, tracked)
+		}
+		return nil
+	}
+
+	// add the record to the incident's thread, keeping the incident open until its parent message has been updated
+	incident := tracked.incident
+	incident.Updates++
+	if slogx.Level(r.Level) > incident.Level {
+		incident.Level = slogx.Level(r.Level)
+	}
+	if state == IncidentStateResolved {
+		incident.ResolvedAt = r.Time
+		incident.State = IncidentStateResolved
+	}
+	msgOpts := append(webhookMessageOptions(message), slack.MsgOptionTS(tracked.timestamp))
+	if _, _, err := client.PostMessageContext(ctx, tracked.channelID, msgOpts...); err != nil {
+		return err
+	}
+	tracked.incident.Level, tracked.incident.Updates = incident.Level, incident.Updates
+
+	// update the parent message
+	parent, err := f.FormatIncident(ctx, incident)
+	if err != nil {
+		return err
+	}
+	if _, _, _, err = client.UpdateMessageContext(ctx, tracked.channelID, tracked.timestamp,
+		webhookMessageOptions(parent)...); err != nil {
+		return err
+	}
+	tracked.incident = incident
+	if state == IncidentStateResolved {
+		t.forget(id, tracked)
+	}
+	return nil
+}
+
+// forget stops tracking the given incident, so that the next record with its ID opens a new incident.
+//
+// The caller must hold the incident's lock.
+func (t *incidentTracker) forget(id string, tracked *trackedIncident) {
+	tracked.closed = true
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.incidents[id] == tracked {
+		delete(t.incidents, id)
+	}
+}
+
+// webhookMessageOptions converts the webhook message into options for posting it through the Web API.
+func webhookMessageOptions(message *slack.WebhookMessage) []slack.MsgOption {
+	opts := []slack.MsgOption{slack.MsgOptionText(message.Text, false)}
+	if message.Blocks != nil {
+		opts = append(opts, slack.MsgOptionBlocks(message.Blocks.BlockSet...))
+	}
+	if len(message.Attachments) > 0 {
+		opts = append(opts, slack.MsgOptionAttachments(message.Attachments...))
+	}
+	return opts
+}
+
+// FormatIncident formats the parent message of an incident.
+//
+// Open incidents are shown in red along with the message which opened them. Resolved incidents are shown in green with
+// the message struck through, along with how long the incident lasted.
+func (f *slackMessageFormatter) FormatIncident(ctx context.Context, incident Incident) (*slack.WebhookMessage,
+	error) {
+
+	handlerCtx := f.options.AddToContext(ctx)
+	formatTime := func(t time.Time) (string, error) {
+		return f.formatRecordTime(handlerCtx, incident.Level, t)
+	}
+
+	// build the summary and details of the incident
+	color := IncidentOpenColor
+	title := fmt.Sprintf(":rotating_light: *Incident `%s` opened*", incident.ID)
+	msg := f.options.MessageRendering.mrkdwn(incident.Message)
+	details := []string{}
+	if !incident.OpenedAt.IsZero() {
+		opened, err := formatTime(incident.OpenedAt)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, "Opened at: "+opened)
+	}
+	if incident.State == IncidentStateResolved {
+		color = IncidentResolvedColor
+		title = fmt.Sprintf(":white_check_mark: *Incident `%s` resolved*", incident.ID)
+		msg = strikethrough(msg)
+		if !incident.ResolvedAt.IsZero() {
+			resolved, err := formatTime(incident.ResolvedAt)
+			if err != nil {
+				return nil, err
+			}
+			details = append(details, "Resolved at: "+resolved)
+			if !incident.OpenedAt.IsZero() {
+				duration := incident.ResolvedAt.Sub(incident.OpenedAt).Round(time.Second)
+				details = append(details, "Duration: "+duration.String())
+			}
+		}
+	}
+	details = append(details, fmt.Sprintf("Updates: %d", incident.Updates))
+	if f.options.ApplicationName != "" {
+		details = append([]string{f.options.ApplicationName}, details...)
+	}
+
+	// build the message
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, title+"\n"+msg, false, false), nil, nil),
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, strings.Join(details, "  |  "), false,
+			false)),
+	}
+	return &slack.WebhookMessage{
+		Attachments: []slack.Attachment{
+			{
+				Blocks: slack.Blocks{BlockSet: blocks},
+				Color:  color,
+			},
+		},
+		Text: fmt.Sprintf("Incident %s %s: %s", incident.ID, incident.State, incident.Message),
+	}, nil
+}
+
+// strikethrough formats each non-empty line of the text as struck through.
+func strikethrough(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			lines[i] = "~" + l + "~"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,195 @@
+package slogxslack_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestIncidentLifecycle(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Incidents: &slogxslack.IncidentOptions{
+			APIURL:  server.APIURL(),
+			Channel: "C0000000000",
+			Token:   slogxslack.NewSecret("xoxb-test"),
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler)
+	logger.Error("database failing", slog.String("incident", "db"), slog.String("incident_state", "open"))
+	logger.Warn("database still failing", slog.String("incident", "db"))
+	logger.Info("unrelated")
+	logger.Info("database recovered", slog.String("incident", "db"), slog.String("incident_state", "resolved"))
+
+	messages := server.Messages()
+	endpoints := []string{}
+	for _, m := range messages {
+		endpoints = append(endpoints, m.Endpoint)
+	}
+	expected := []string{
+		slacktest.EndpointPostMessage, slacktest.EndpointPostMessage,
+		slacktest.EndpointPostMessage, slacktest.EndpointUpdateMessage,
+		slacktest.EndpointWebhook,
+		slacktest.EndpointPostMessage, slacktest.EndpointUpdateMessage,
+	}
+	if len(endpoints) != len(expected) {
+		t.Fatalf("expected endpoints %v, got %v", expected, endpoints)
+	}
+	for i := range expected {
+		if endpoints[i] != expected[i] {
+			t.Fatalf("expected endpoints %v, got %v", expected, endpoints)
+		}
+	}
+
+	parent := messages[0]
+	slacktest.AssertContainsText(t, parent, "Incident `db` opened")
+	for _, i := range []int{1, 2, 5} {
+		if messages[i].ThreadTimestamp != parent.Timestamp {
+			t.Errorf("expected message %d to be a reply to the parent message", i)
+		}
+	}
+	resolved := messages[6]
+	if resolved.Timestamp != parent.Timestamp {
+		t.Error("expected the parent message to be edited")
+	}
+	slacktest.AssertContainsText(t, resolved, "Incident `db` resolved")
+	slacktest.AssertContainsText(t, resolved, "~database failing~")
+	if len(resolved.Attachments) != 1 || resolved.Attachments[0].Color != slogxslack.IncidentResolvedColor {
+		t.Errorf("expected the resolved incident to be shown in green")
+	}
+
+	// a new record for the same incident opens a new incident
+	logger.Error("database failing again", slog.String("incident", "db"))
+	messages = server.Messages()
+	if reopened := messages[len(messages)-2]; reopened.ThreadTimestamp != "" || reopened.Timestamp == parent.Timestamp {
+		t.Error("expected a new parent message for the reopened incident")
+	}
+}
+
+func TestIncidentIgnoresContextOverrides(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Incidents: &slogxslack.IncidentOptions{
+			APIURL:  server.APIURL(),
+			Channel: "C0000000000",
+			Token:   slogxslack.NewSecret("xoxb-test"),
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler)
+	ctx := slogxslack.WithThread(slogxslack.WithChannel(context.Background(), "#elsewhere"), "1111.2222")
+	logger.ErrorContext(ctx, "database failing", slog.String("incident", "db"))
+	logger.ErrorContext(ctx, "database still failing", slog.String("incident", "db"))
+
+	messages := server.Messages()
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(messages))
+	}
+	parent := messages[0]
+	for i, m := range messages[:3] {
+		if m.Endpoint != slacktest.EndpointPostMessage || m.Channel != "C0000000000" {
+			t.Errorf("expected message %d to be posted to the incident's channel, got %s", i, m.Channel)
+		}
+		if i > 0 && m.ThreadTimestamp != parent.Timestamp {
+			t.Errorf("expected message %d to be a reply to the incident's parent message", i)
+		}
+	}
+	if parent.ThreadTimestamp != "" {
+		t.Error("expected the incident's parent message not to be posted to the overridden thread")
+	}
+}
+
+func TestIncidentsDeliveredConcurrently(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		EnableAsync: true,
+		Incidents: &slogxslack.IncidentOptions{
+			APIURL:  server.APIURL(),
+			Channel: "C0000000000",
+			Token:   slogxslack.NewSecret("xoxb-test"),
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+
+	// a slow post for one incident does not hold up the records of another
+	server.InjectFault(1, slacktest.Fault{Delay: time.Second, Endpoint: slacktest.EndpointPostMessage})
+	logger := slog.New(handler)
+	logger.Error("cache failing", slog.String("incident", "cache"))
+	time.Sleep(50 * time.Millisecond)
+	logger.Error("queue failing", slog.String("incident", "queue"))
+
+	messages := server.WaitForMessages(t, 2, 500*time.Millisecond)
+	slacktest.AssertContainsText(t, messages[0], "Incident `queue` opened")
+	if err := handler.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %s", err.Error())
+	}
+	if n := len(server.Messages()); n != 4 {
+		t.Errorf("expected both incidents to be opened, got %d messages", n)
+	}
+}
+
+func TestIncidentResolvedAfterFailedUpdate(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Incidents: &slogxslack.IncidentOptions{
+			APIURL:  server.APIURL(),
+			Channel: "C0000000000",
+			Token:   slogxslack.NewSecret("xoxb-test"),
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler).WithGroup("job")
+	logger.Error("database failing", slog.String("incident", "db"))
+	server.InjectFault(1, slacktest.Fault{
+		Endpoint:   slacktest.EndpointUpdateMessage,
+		StatusCode: http.StatusInternalServerError,
+	})
+	logger.Info("database recovered", slog.String("incident", "db"), slog.String("incident_state", "resolved"))
+	if stats := handler.Stats(); stats.Failed != 1 {
+		t.Fatalf("expected the failed update to be reported, got %+v", stats)
+	}
+
+	// resolving the incident again edits the same parent message
+	logger.Info("database recovered", slog.String("incident", "db"), slog.String("incident_state", "resolved"))
+	messages := server.Messages()
+	if len(messages) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(messages))
+	}
+	parent := messages[0]
+	for i, m := range messages {
+		if m.Endpoint == slacktest.EndpointWebhook {
+			t.Errorf("expected message %d to belong to the incident", i)
+		}
+	}
+	resolved := messages[4]
+	if resolved.Endpoint != slacktest.EndpointUpdateMessage || resolved.Timestamp != parent.Timestamp {
+		t.Fatal("expected the parent message to be edited")
+	}
+	slacktest.AssertContainsText(t, resolved, "Incident `db` resolved")
+}
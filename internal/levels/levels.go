@@ -0,0 +1,49 @@
+// Package levels parses the names of log levels, including the additional levels defined by slogx.
+//
+// It is shared by the slogx-slack and logparse packages so that neither has to import the other.
+package levels
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"go.innotegrity.dev/slogx"
+)
+
+// names maps the lowercase name of each level to the level.
+var names = map[string]slogx.Level{
+	"trace":   slogx.LevelTrace,
+	"debug":   slogx.LevelDebug,
+	"info":    slogx.LevelInfo,
+	"notice":  slogx.LevelNotice,
+	"warn":    slogx.LevelWarn,
+	"warning": slogx.LevelWarn,
+	"error":   slogx.LevelError,
+	"fatal":   slogx.LevelFatal,
+	"panic":   slogx.LevelPanic,
+}
+
+// Parse parses the name of a level, including the additional levels defined by slogx.
+//
+// Names are case-insensitive and may include an offset (eg: error+2 or INFO-1). Numeric levels are also accepted.
+func Parse(name string) (slog.Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if n, err := strconv.Atoi(name); err == nil {
+		return slog.Level(n), nil
+	}
+	offset := 0
+	if i := strings.IndexAny(name, "+-"); i > 0 {
+		n, err := strconv.Atoi(name[i:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid level offset in '%s'", name)
+		}
+		name, offset = name[:i], n
+	}
+	level, ok := names[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown level '%s'", name)
+	}
+	return level.Level() + slog.Level(offset), nil
+}
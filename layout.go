@@ -0,0 +1,147 @@
+package slogxslack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+)
+
+// MessageLayout determines how the formatter arranges the blocks of a message.
+type MessageLayout string
+
+const (
+	// MessageLayoutDefault displays the application name and level, followed by the time and source, the message and
+	// the attributes.
+	MessageLayoutDefault MessageLayout = "default"
+
+	// MessageLayoutHeader displays a header block with a title built from the level, message and any attributes
+	// promoted to the header (see HeaderAttrs) first, followed by a one-line summary of the key attributes (see
+	// KeyAttrs) and then the rest of the message.
+	//
+	// The message itself is only repeated beneath the header when the title does not contain all of it.
+	MessageLayoutHeader MessageLayout = "header"
+)
+
+// MaxHeaderLength is the maximum number of characters Slack allows in the text of a header block.
+const MaxHeaderLength = 150
+
+// FormatHeaderFn is a function which returns the title to display in the header block of the message.
+//
+// Titles longer than MaxHeaderLength characters are trimmed by the formatter.
+type FormatHeaderFn func(ctx context.Context, level slog.Leveler, msg string) (string, error)
+
+// FormatSummaryFn is a function which returns the one-line summary of the key attributes.
+//
+// The attributes are passed in the order of the KeyAttrs option, with their full, dotted keys. Only key attributes
+// present in the record are passed.
+type FormatSummaryFn func(ctx context.Context, level slog.Leveler, attrs []slog.Attr) (string, error)
+
+// validate determines whether or not the layout is known.
+func (l MessageLayout) validate() error {
+	switch l {
+	case "", MessageLayoutDefault, MessageLayoutHeader:
+		return nil
+	}
+	return fmt.Errorf("unknown message layout %q", l)
+}
+
+// headerBlock returns the header block for the header layout, with the values of any attributes promoted to the
+// header appended to the title.
+//
+// The title of the header is also returned so the caller can determine whether or not it contains the whole message.
+func (f slackMessageFormatter) headerBlock(ctx context.Context, level slogx.Level, msg string,
+	headerAttrs []slog.Attr) (slack.Block, string, error) {
+
+	var title string
+	var err error
+	if f.options.HeaderFormatter != nil {
+		title, err = f.options.HeaderFormatter(ctx, level, msg)
+	} else {
+		title, err = formatSlackMessageHeaderDefault(ctx, level, msg)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	suffix := ""
+	for _, attr := range headerAttrs {
+		if !f.attrSelected(attr.Key) {
+			continue
+		}
+		key, value, err := f.formatAttr(ctx, level, attr.Key, attr.Value)
+		if err != nil {
+			return nil, "", err
+		}
+		suffix += fmt.Sprintf("  |  %s: %s", key, value.String())
+	}
+	title = trimText(title, MaxHeaderLength-utf8.RuneCountInString(suffix)) + suffix
+	title = trimText(title, MaxHeaderLength)
+	return slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, title, true, false)), title, nil
+}
+
+// summaryBlock returns the context block summarizing the key attributes, or nil if there is nothing to summarize.
+func (f slackMessageFormatter) summaryBlock(ctx context.Context, level slogx.Level, timestamp time.Time,
+	keyAttrs []slog.Attr) (slack.Block, error) {
+
+	if len(keyAttrs) == 0 {
+		return nil, nil
+	}
+	summary := ""
+	if f.options.SummaryFormatter != nil {
+		var err error
+		if summary, err = f.options.SummaryFormatter(ctx, level, keyAttrs); err != nil {
+			return nil, err
+		}
+	} else {
+		texts := []string{}
+		for _, attr := range keyAttrs {
+			element, err := f.attrToElement(ctx, level, timestamp, attr.Key, attr.Value, 0)
+			if err != nil {
+				return nil, err
+			}
+			if text, ok := element.(slack.TextBlockObject); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+		summary = strings.Join(texts, "  |  ")
+	}
+	if summary == "" {
+		return nil, nil
+	}
+	return slack.NewContextBlock("", slack.TextBlockObject{
+		Type: slack.MarkdownType,
+		Text: summary,
+	}), nil
+}
+
+// formatSlackMessageHeaderDefault returns a title made up of the level's emoji and label followed by the first line of
+// the message (eg: ":no_entry: ERROR: disk full").
+func formatSlackMessageHeaderDefault(ctx context.Context, level slog.Leveler, msg string) (string, error) {
+	if i := strings.IndexByte(msg, '\n'); i != -1 {
+		msg = strings.TrimSpace(msg[:i])
+	}
+	style := GetSlackMessageFormatterOptionsFromContext(ctx).Levels.Lookup(level)
+	title := fmt.Sprintf("%s: %s", strings.ToUpper(style.Label), msg)
+	if style.Emoji != "" {
+		title = style.Emoji + " " + title
+	}
+	return title, nil
+}
+
+// trimText trims the text to the given number of characters, ending it with an ellipsis if it was trimmed.
+func trimText(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	if length < 1 {
+		return ""
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:length-1])) + "…"
+}
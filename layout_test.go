@@ -0,0 +1,94 @@
+package slogxslack_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+func TestHeaderLayout(t *testing.T) {
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.KeyAttrs = []string{"service", "env", "host"}
+	opts.Layout = slogxslack.MessageLayoutHeader
+	f := slogxslack.NewSlackMessageFormatter(opts)
+
+	attrs := []slog.Attr{
+		slog.String("host", "web-1"),
+		slog.String("service", "api"),
+		slog.Int("status", 500),
+	}
+	message, err := f.FormatRecord(context.Background(), time.Now(), slogx.LevelError, 0, "disk full", attrs)
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	blocks := message.Blocks.BlockSet
+	header, ok := blocks[0].(*slack.HeaderBlock)
+	if !ok || header.Text.Text != ":no_entry: ERROR: disk full" {
+		t.Fatalf("expected a header block with an emoji-prefixed title first, got %#v", blocks[0])
+	}
+	summary, ok := blocks[1].(*slack.ContextBlock)
+	if !ok {
+		t.Fatalf("expected the key attribute summary beneath the header, got %#v", blocks[1])
+	}
+	text := summary.ContextElements.Elements[0].(slack.TextBlockObject).Text
+	if text != "*service*: `api`  |  *host*: `web-1`" {
+		t.Errorf("expected key attributes to be summarized in order, got %q", text)
+	}
+
+	texts := []string{}
+	for _, b := range blocks[2:] {
+		if c, ok := b.(*slack.ContextBlock); ok {
+			for _, e := range c.ContextElements.Elements {
+				if o, ok := e.(slack.TextBlockObject); ok {
+					texts = append(texts, o.Text)
+				}
+			}
+		}
+		if _, ok := b.(slack.SectionBlock); ok {
+			t.Error("expected the message not to be repeated when the header contains all of it")
+		}
+	}
+	all := strings.Join(texts, "\n")
+	if strings.Contains(all, "web-1") || !strings.Contains(all, "*status*: `500`") {
+		t.Errorf("expected only the remaining attributes after the summary, got %q", all)
+	}
+}
+
+func TestHeaderLayoutFormatters(t *testing.T) {
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.KeyAttrs = []string{"env"}
+	opts.Layout = slogxslack.MessageLayoutHeader
+	opts.HeaderFormatter = func(ctx context.Context, level slog.Leveler, msg string) (string, error) {
+		return strings.Repeat("x", slogxslack.MaxHeaderLength+10), nil
+	}
+	opts.SummaryFormatter = func(ctx context.Context, level slog.Leveler, attrs []slog.Attr) (string, error) {
+		return "env=" + attrs[0].Value.String(), nil
+	}
+	f := slogxslack.NewSlackMessageFormatter(opts)
+
+	message, err := f.FormatRecord(context.Background(), time.Now(), slogx.LevelInfo, 0, "started",
+		[]slog.Attr{slog.String("env", "prod")})
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	blocks := message.Blocks.BlockSet
+	title := blocks[0].(*slack.HeaderBlock).Text.Text
+	if utf8.RuneCountInString(title) != slogxslack.MaxHeaderLength || !strings.HasSuffix(title, "…") {
+		t.Errorf("expected the title to be trimmed to %d characters, got %q", slogxslack.MaxHeaderLength, title)
+	}
+	summary := blocks[1].(*slack.ContextBlock).ContextElements.Elements[0].(slack.TextBlockObject)
+	if text := summary.Text; text != "env=prod" {
+		t.Errorf("expected the summary formatter to be used, got %q", text)
+	}
+	section, ok := blocks[len(blocks)-1].(slack.SectionBlock)
+	if !ok || section.Text.Text != "started" {
+		t.Errorf("expected the message to be displayed when the header does not contain it")
+	}
+}
@@ -0,0 +1,209 @@
+package slogxslack
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"go.innotegrity.dev/slogx"
+)
+
+// levelStyler is implemented by formatters which hold a level registry.
+type levelStyler interface {
+	// levelRegistry should return the registry holding the styles of levels.
+	levelRegistry() *LevelRegistry
+
+	// levelStyle should return the style of the given level.
+	levelStyle(level slog.Leveler) LevelStyle
+}
+
+// LevelStyle describes how records of a level are displayed and routed.
+type LevelStyle struct {
+	// Channel is the channel records of the level are posted to by default.
+	//
+	// A channel added to the context using WithChannel() takes precedence. If empty, records are posted to the
+	// webhook's default channel.
+	Channel string
+
+	// Color is the color of the attachment used for records of the level when posting to Slack-compatible targets
+	// (eg: "#e01e5a").
+	//
+	// If empty, the attachment is not colored.
+	Color string
+
+	// Emoji is the emoji displayed before the level's label (eg: ":no_entry:").
+	//
+	// If empty, only the label is displayed.
+	Emoji string
+
+	// Label is the name of the level (eg: "error").
+	//
+	// If empty, the level's String() value is used.
+	Label string
+
+	// Mentions are the users, user groups and special mentions added to records of the level by default (see
+	// WithMentions()).
+	//
+	// Mentions added to the context take precedence. If empty, no mentions are added by default.
+	Mentions []string
+}
+
+// LevelRoute describes where records of a range of levels are posted by default and who they mention.
+type LevelRoute struct {
+	// Channel is the channel records of the levels are posted to by default.
+	Channel string `json:"channel,omitempty"`
+
+	// MaxLevel is the name of the highest level of the range.
+	MaxLevel string `json:"max_level"`
+
+	// Mentions are the users, user groups and special mentions added to records of the levels by default.
+	Mentions []string `json:"mentions,omitempty"`
+
+	// MinLevel is the name of the lowest level of the range.
+	MinLevel string `json:"min_level"`
+}
+
+// LevelRegistry maps levels, or ranges of levels, to the style used to display them.
+//
+// Levels which are not defined use the style of the nearest defined level, with the offset from that level added to
+// the label (eg: "error+2"). When a level is equally near two defined levels, the lower level is used. A registry
+// should not be modified once it is being used by a formatter.
+type LevelRegistry struct {
+	// unexported variables
+	ranges []levelRange
+}
+
+// levelRange holds the style of an inclusive range of levels.
+type levelRange struct {
+	max   slog.Level
+	min   slog.Level
+	style LevelStyle
+}
+
+// defaultLevelRegistry is the registry used when a formatter's Levels option is nil.
+var defaultLevelRegistry = DefaultLevelRegistry()
+
+// NewLevelRegistry creates and returns a new registry without any levels defined.
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{}
+}
+
+// DefaultLevelRegistry returns a registry defining an emoji, label and color for each of the levels defined by slogx.
+func DefaultLevelRegistry() *LevelRegistry {
+	return NewLevelRegistry().
+		Define(slogx.LevelTrace, LevelStyle{Color: "#9e9ea6", Emoji: ":eyes:", Label: "trace"}).
+		Define(slogx.LevelDebug, LevelStyle{Color: "#9e9ea6", Emoji: ":ladybug:", Label: "debug"}).
+		Define(slogx.LevelInfo, LevelStyle{Color: "#36c5f0", Emoji: ":information_source:", Label: "info"}).
+		Define(slogx.LevelNotice, LevelStyle{Color: "#36c5f0", Emoji: ":grey_exclamation:", Label: "notice"}).
+		Define(slogx.LevelWarn, LevelStyle{Color: "#ecb22e", Emoji: ":warning:", Label: "warn"}).
+		Define(slogx.LevelError, LevelStyle{Color: "#e01e5a", Emoji: ":no_entry:", Label: "error"}).
+		Define(slogx.LevelFatal, LevelStyle{Color: "#e01e5a", Emoji: ":rotating_light:", Label: "fatal"}).
+		Define(slogx.LevelPanic, LevelStyle{Color: "#e01e5a", Emoji: ":sos:", Label: "panic"})
+}
+
+// Define sets the style of the given level and returns the registry.
+//
+// Any style previously defined for exactly the same level is replaced.
+func (r *LevelRegistry) Define(level slog.Leveler, style LevelStyle) *LevelRegistry {
+	return r.DefineRange(level, level, style)
+}
+
+// DefineRange sets the style of every level from min to max, inclusive, and returns the registry.
+//
+// Levels within the range are displayed using the style's label without an offset. Any style previously defined for
+// exactly the same range is replaced. If ranges overlap, the range defined last is used.
+func (r *LevelRegistry) DefineRange(min, max slog.Leveler, style LevelStyle) *LevelRegistry {
+	lr := levelRange{max: max.Level(), min: min.Level(), style: style}
+	if lr.min > lr.max {
+		lr.min, lr.max = lr.max, lr.min
+	}
+	lr.style.Mentions = append([]string{}, style.Mentions...)
+	for i, existing := range r.ranges {
+		if existing.min == lr.min && existing.max == lr.max {
+			r.ranges = append(r.ranges[:i], r.ranges[i+1:]...)
+			break
+		}
+	}
+	r.ranges = append(r.ranges, lr)
+	return r
+}
+
+// Lookup returns the style used to display the given level.
+//
+// If the level is not within a defined level or range, the style of the nearest one is returned with the offset added
+// to its label. If the registry is nil, the default registry is used. If no levels are defined, the style only holds
+// the level's String() value as its label.
+func (r *LevelRegistry) Lookup(level slog.Leveler) LevelStyle {
+	if r == nil {
+		r = defaultLevelRegistry
+	}
+	l := level.Level()
+	for i := len(r.ranges) - 1; i >= 0; i-- {
+		if lr := r.ranges[i]; l >= lr.min && l <= lr.max {
+			return lr.style.withLabel(level, 0)
+		}
+	}
+	if len(r.ranges) == 0 {
+		return LevelStyle{Label: fmt.Sprintf("%s", level)}
+	}
+
+	// find the nearest range, preferring the lower range when two are equally near
+	ranges := append([]levelRange{}, r.ranges...)
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].min < ranges[j].min
+	})
+	nearest, offset := ranges[0], slog.Level(0)
+	for i, lr := range ranges {
+		o := l - lr.max
+		if l < lr.min {
+			o = l - lr.min
+		}
+		if i == 0 || absLevel(o) < absLevel(offset) {
+			nearest, offset = lr, o
+		}
+	}
+	return nearest.style.withLabel(level, offset)
+}
+
+// Routes returns the channel and mentions of every range of levels in the registry which has either, in the order the
+// ranges were defined.
+//
+// If ranges overlap, the range defined last is used. If the receiver is nil, the routes of the default registry are
+// returned.
+func (r *LevelRegistry) Routes() []LevelRoute {
+	if r == nil {
+		r = defaultLevelRegistry
+	}
+	routes := []LevelRoute{}
+	for _, lr := range r.ranges {
+		if lr.style.Channel == "" && len(lr.style.Mentions) == 0 {
+			continue
+		}
+		routes = append(routes, LevelRoute{
+			Channel:  lr.style.Channel,
+			MaxLevel: slogx.Level(lr.max).String(),
+			Mentions: append([]string{}, lr.style.Mentions...),
+			MinLevel: slogx.Level(lr.min).String(),
+		})
+	}
+	return routes
+}
+
+// withLabel returns a copy of the style whose label includes the offset from the defined level, if any.
+func (s LevelStyle) withLabel(level slog.Leveler, offset slog.Level) LevelStyle {
+	if s.Label == "" {
+		s.Label = fmt.Sprintf("%s", level)
+	} else if offset != 0 {
+		s.Label = fmt.Sprintf("%s%+d", s.Label, int(offset))
+	}
+	s.Mentions = append([]string{}, s.Mentions...)
+	return s
+}
+
+// absLevel returns the absolute value of the level offset.
+func absLevel(l slog.Level) slog.Level {
+	if l < 0 {
+		return -l
+	}
+	return l
+}
@@ -0,0 +1,77 @@
+package slogxslack_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+func TestLevelRegistryLookup(t *testing.T) {
+	levels := slogxslack.DefaultLevelRegistry().
+		Define(slogx.LevelError+2, slogxslack.LevelStyle{Emoji: ":fire:", Label: "critical"}).
+		DefineRange(slog.Level(20), slog.Level(29), slogxslack.LevelStyle{Label: "page"})
+	tests := map[slog.Level]string{
+		slogx.LevelError.Level():      "error",
+		slogx.LevelError.Level() + 1:  "error+1",
+		slogx.LevelError.Level() + 2:  "critical",
+		slogx.LevelError.Level() + 3:  "critical+1",
+		slogx.LevelInfo.Level() + 1:   "info+1",
+		slogx.LevelTrace.Level() - 4:  "trace-4",
+		slog.Level(18):                "panic+2",
+		slog.Level(25):                "page",
+		slog.Level(31):                "page+2",
+		slogx.LevelNotice.Level() + 1: "notice+1",
+	}
+	for level, expected := range tests {
+		if label := levels.Lookup(level).Label; label != expected {
+			t.Errorf("expected level %d to be labelled %q, got %q", level, expected, label)
+		}
+	}
+	if style := levels.Lookup(slogx.LevelError + 1); style.Emoji != ":no_entry:" || style.Color != "#e01e5a" {
+		t.Errorf("expected an undefined level to use the style of the nearest level, got %+v", style)
+	}
+
+	var nilRegistry *slogxslack.LevelRegistry
+	if style := nilRegistry.Lookup(slogx.LevelWarn); style.Label != "warn" || style.Emoji != ":warning:" {
+		t.Errorf("expected a nil registry to use the default levels, got %+v", style)
+	}
+	if style := slogxslack.NewLevelRegistry().Lookup(slog.LevelWarn); style.Label != "WARN" {
+		t.Errorf("expected an empty registry to use the level's name, got %+v", style)
+	}
+}
+
+func TestLevelRegistryRouting(t *testing.T) {
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.Levels = slogxslack.DefaultLevelRegistry().Define(slogx.LevelError+2, slogxslack.LevelStyle{
+		Channel:  "#incidents",
+		Emoji:    ":fire:",
+		Label:    "critical",
+		Mentions: []string{"here"},
+	})
+	f := slogxslack.NewSlackMessageFormatter(opts)
+
+	message, err := f.FormatRecord(context.Background(), time.Now(), slogx.LevelError+2, 0, "db down", nil)
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	if message.Channel != "#incidents" || message.Text != "<!here> db down" {
+		t.Errorf("expected the level's default channel and mentions, got %q and %q", message.Channel, message.Text)
+	}
+	level := message.Blocks.BlockSet[1].(*slack.ContextBlock).ContextElements.Elements[0].(slack.TextBlockObject)
+	if level.Text != ":fire: critical" {
+		t.Errorf("expected the level's emoji and label, got %q", level.Text)
+	}
+
+	ctx := slogxslack.WithMentions(context.Background(), "U012AB3CD")
+	if message, err = f.FormatRecord(ctx, time.Now(), slogx.LevelError+2, 0, "db down", nil); err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	if message.Text != "<@U012AB3CD> db down" {
+		t.Errorf("expected mentions in the context to take precedence, got %q", message.Text)
+	}
+}
@@ -0,0 +1,232 @@
+package slogxslack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// ErrHandlerShutdown is returned when a record is passed to a handler which has already been shut down.
+var ErrHandlerShutdown = errors.New("slack handler has been shut down")
+
+// AbandonedRecordsError is returned when the handler is shut down before all pending records could be delivered.
+type AbandonedRecordsError struct {
+	// Count is the number of records which were still pending when the handler stopped waiting for them.
+	Count int
+
+	// Err is the reason the handler stopped waiting for the pending records.
+	Err error
+}
+
+// Error returns the string version of the error.
+func (e *AbandonedRecordsError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d pending record(s) abandoned: %s", e.Count, e.Err.Error())
+	}
+	return fmt.Sprintf("%d pending record(s) abandoned", e.Count)
+}
+
+// Unwrap returns the reason the handler stopped waiting for the pending records.
+func (e *AbandonedRecordsError) Unwrap() error {
+	return e.Err
+}
+
+// HandlerStats holds the delivery statistics for a handler and every handler derived from it.
+type HandlerStats struct {
+	// Delivered is the number of messages, including heartbeat messages, successfully posted to Slack.
+	Delivered uint64 `json:"delivered"`
+
+	// Dropped is the number of records which were discarded, either because the handler was paused, because they
+	// exceeded the handler's rate limit or because the formatter returned a nil message.
+	Dropped uint64 `json:"dropped"`
+
+	// Failed is the number of records and heartbeat messages which could not be formatted or posted to Slack.
+	Failed uint64 `json:"failed"`
+
+	// Held is the number of records held by schedule windows whose action is ScheduleActionHold.
+	Held uint64 `json:"held"`
+
+	// Pending is the number of records currently being delivered.
+	Pending int `json:"pending"`
+}
+
+// handlerLifecycle tracks the records being delivered by a handler and every handler derived from it.
+type handlerLifecycle struct {
+	// unexported variables
+	cancel  context.CancelFunc
+	changed chan struct{}
+	closed  bool
+	ctx     context.Context
+	errs    []error
+	levels  map[slog.Level]uint64
+	mu      sync.Mutex
+	paused  bool
+	pending int
+	stats   HandlerStats
+}
+
+// newHandlerLifecycle creates a new lifecycle object.
+func newHandlerLifecycle() *handlerLifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &handlerLifecycle{
+		cancel:  cancel,
+		changed: make(chan struct{}),
+		ctx:     ctx,
+		errs:    []error{},
+		levels:  map[slog.Level]uint64{},
+	}
+}
+
+// begin registers a new pending record.
+//
+// If the handler has already been shut down, ErrHandlerShutdown is returned.
+func (l *handlerLifecycle) begin() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrHandlerShutdown
+	}
+	l.pending++
+	return nil
+}
+
+// end marks a pending record as finished, saving the delivery error (if any).
+func (l *handlerLifecycle) end(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending--
+	if err != nil {
+		l.errs = append(l.errs, err)
+	}
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// count records that a record with the given level was passed to the handler.
+func (l *handlerLifecycle) count(level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.levels[level]++
+}
+
+// delivered records whether or not a record was successfully posted to Slack.
+func (l *handlerLifecycle) delivered(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		l.stats.Failed++
+	} else {
+		l.stats.Delivered++
+	}
+}
+
+// dropped records that a record was discarded without being posted to Slack.
+func (l *handlerLifecycle) dropped() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Dropped++
+}
+
+// heldRecord records that a record was held by a schedule window.
+func (l *handlerLifecycle) heldRecord() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Held++
+}
+
+// isPaused determines whether or not posting to Slack is currently paused.
+func (l *handlerLifecycle) isPaused() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.paused
+}
+
+// setPaused pauses or resumes posting to Slack.
+func (l *handlerLifecycle) setPaused(paused bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.paused = paused
+}
+
+// snapshot returns a copy of the current delivery statistics.
+func (l *handlerLifecycle) snapshot() HandlerStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := l.stats
+	stats.Pending = l.pending
+	return stats
+}
+
+// takeLevelCounts returns the number of records passed to the handler at each level since the last call.
+func (l *handlerLifecycle) takeLevelCounts() map[slog.Level]uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	levels := l.levels
+	l.levels = map[slog.Level]uint64{}
+	return levels
+}
+
+// bind returns a context which is cancelled when the lifecycle gives up on any pending records.
+//
+// The returned function must be called to release resources once the context is no longer needed.
+func (l *handlerLifecycle) bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(l.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// drain waits for any pending records to finish and returns any delivery errors which occurred since the last drain.
+//
+// If continueOnError is false, the function stops waiting as soon as a delivery error is encountered. If the
+// function stops waiting before all pending records are finished, the number of records still pending is returned.
+func (l *handlerLifecycle) drain(ctx context.Context, continueOnError bool) (int, []error) {
+	for {
+		l.mu.Lock()
+		if l.pending == 0 || (!continueOnError && len(l.errs) > 0) {
+			pending, errs := l.pending, l.errs
+			l.errs = []error{}
+			l.mu.Unlock()
+			return pending, errs
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			l.mu.Lock()
+			pending, errs := l.pending, l.errs
+			l.errs = []error{}
+			l.mu.Unlock()
+			return pending, errs
+		}
+	}
+}
+
+// shutdown stops accepting new records and waits for any pending records to finish.
+//
+// Any records which are still pending when the function stops waiting are abandoned and their deliveries cancelled.
+func (l *handlerLifecycle) shutdown(ctx context.Context, continueOnError bool) error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+
+	pending, errs := l.drain(ctx, continueOnError)
+	l.cancel()
+	if pending > 0 {
+		reason := ctx.Err()
+		if reason == nil && len(errs) > 0 {
+			reason = errors.New("stopped after the first delivery error")
+		}
+		errs = append(errs, &AbandonedRecordsError{
+			Count: pending,
+			Err:   reason,
+		})
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,6 @@
+// Package logparse rebuilds log/slog records from lines of log output.
+//
+// This allows the output of processes which cannot be changed, such as legacy applications and cron jobs, to be fed
+// into any slog.Handler, including the Slack handler. Use ParseJSON() for lines written by slog.JSONHandler,
+// ParseText() for lines written by slog.TextHandler and ParseLogfmt() for lines written by other logfmt libraries.
+package logparse
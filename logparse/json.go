@@ -0,0 +1,110 @@
+package logparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// ParseJSON parses a line written by slog.JSONHandler into a record.
+//
+// The time, level and message are taken from the slog.TimeKey, slog.LevelKey and slog.MessageKey fields. Levels are
+// parsed in the same way as ParseLevel() from the slogx-slack package, so the additional levels defined by slogx are
+// supported. If the line has no level, slog.LevelInfo is used.
+//
+// The source location, if present, cannot be converted back into a program counter, so it is added as a "source"
+// attribute formatted as FILE:LINE instead. Every other field is added as an attribute in the order it appears in the
+// line, with nested objects added as groups. Integers are added as int64 values, other numbers as float64 values and
+// arrays as []any values.
+func ParseJSON(line []byte) (slog.Record, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return slog.Record{}, ErrEmptyLine
+	}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if t, err := decoder.Token(); err != nil {
+		return slog.Record{}, fmt.Errorf("invalid JSON log line: %w", err)
+	} else if t != json.Delim('{') {
+		return slog.Record{}, errors.New("invalid JSON log line: expected an object")
+	}
+	attrs, err := decodeObject(decoder)
+	if err != nil {
+		return slog.Record{}, fmt.Errorf("invalid JSON log line: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return slog.Record{}, errors.New("invalid JSON log line: unexpected data after the object")
+	}
+	return buildRecord(attrs)
+}
+
+// decodeObject decodes the remaining members of an object whose opening delimiter has already been read.
+func decodeObject(decoder *json.Decoder) ([]slog.Attr, error) {
+	attrs := []slog.Attr{}
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an object key, got %v", t)
+		}
+		value, err := decodeValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// decodeValue decodes the next value.
+func decodeValue(decoder *json.Decoder) (slog.Value, error) {
+	t, err := decoder.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+	switch v := t.(type) {
+	case json.Delim:
+		if v == '{' {
+			attrs, err := decodeObject(decoder)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			return slog.GroupValue(attrs...), nil
+		}
+		values := []any{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			values = append(values, value.Any())
+		}
+		if _, err := decoder.Token(); err != nil {
+			return slog.Value{}, err
+		}
+		return slog.AnyValue(values), nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return slog.Int64Value(n), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return slog.Value{}, err
+		}
+		return slog.Float64Value(f), nil
+	case string:
+		return slog.StringValue(v), nil
+	case bool:
+		return slog.BoolValue(v), nil
+	}
+	return slog.AnyValue(nil), nil
+}
@@ -0,0 +1,79 @@
+package logparse_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.innotegrity.dev/slogx"
+	"go.innotegrity.dev/slogx-slack/logparse"
+)
+
+func TestParseJSONRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true, Level: slogx.LevelTrace}))
+	logger.Log(context.Background(), slogx.LevelFatal.Level(), "disk full",
+		slog.Int("free", 0),
+		slog.Float64("ratio", 0.5),
+		slog.Bool("retry", true),
+		slog.Group("disk", slog.String("device", "/dev/sda1"), slog.Group("usage", slog.Int("used", 100))),
+	)
+
+	r, err := logparse.ParseJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse line: %s", err.Error())
+	}
+	if r.Message != "disk full" {
+		t.Errorf("expected message 'disk full', got %q", r.Message)
+	}
+	if r.Level != slogx.LevelFatal.Level() {
+		t.Errorf("expected level %s, got %s", slogx.LevelFatal.String(), r.Level)
+	}
+	if r.Time.IsZero() || time.Since(r.Time) > time.Minute {
+		t.Errorf("expected the time of the record, got %s", r.Time)
+	}
+
+	attrs := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value
+		return true
+	})
+	if v := attrs["free"]; v.Kind() != slog.KindInt64 || v.Int64() != 0 {
+		t.Errorf("expected free to be an integer, got %v", v)
+	}
+	if v := attrs["ratio"]; v.Kind() != slog.KindFloat64 || v.Float64() != 0.5 {
+		t.Errorf("expected ratio to be a float, got %v", v)
+	}
+	if v := attrs["retry"]; v.Kind() != slog.KindBool || !v.Bool() {
+		t.Errorf("expected retry to be a bool, got %v", v)
+	}
+	if v := attrs["source"]; v.Kind() != slog.KindString || !strings.Contains(v.String(), "json_test.go:") {
+		t.Errorf("expected source to be FILE:LINE, got %v", v)
+	}
+	disk := attrs["disk"]
+	if disk.Kind() != slog.KindGroup || len(disk.Group()) != 2 {
+		t.Fatalf("expected disk to be a group with 2 attributes, got %v", disk)
+	}
+	if usage := disk.Group()[1]; usage.Key != "usage" || usage.Value.Kind() != slog.KindGroup {
+		t.Errorf("expected a nested usage group, got %v", usage)
+	}
+}
+
+func TestParseJSONInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":    "   ",
+		"not json": "level=error msg=failed",
+		"array":    `["a"]`,
+		"level":    `{"level":"LOUD","msg":"x"}`,
+		"time":     `{"time":"yesterday","msg":"x"}`,
+		"trailing": `{"msg":"x"} {}`,
+	}
+	for name, line := range tests {
+		if _, err := logparse.ParseJSON([]byte(line)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package logparse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logfmtAliases maps the keys commonly used for the built-in fields in logfmt output to the keys used by log/slog.
+var logfmtAliases = map[string]string{
+	"lvl":       slog.LevelKey,
+	"message":   slog.MessageKey,
+	"severity":  slog.LevelKey,
+	"t":         slog.TimeKey,
+	"timestamp": slog.TimeKey,
+	"ts":        slog.TimeKey,
+}
+
+// logfmtPair is a single key/value pair from a line.
+type logfmtPair struct {
+	key    string
+	quoted bool
+	value  string
+}
+
+// ParseLogfmt parses a logfmt line (eg: level=error msg="backup failed" attempt=2) into a record.
+//
+// The line is parsed the same way as ParseText(), except that the keys commonly used by other logfmt libraries for the
+// built-in fields are also accepted: ts, t and timestamp for the time, lvl and severity for the level and message for
+// the message. A key without a value (eg: "retry" rather than "retry=true") is added as a true boolean attribute.
+func ParseLogfmt(line []byte) (slog.Record, error) {
+	return parseKeyValues(line, logfmtAliases)
+}
+
+// ParseText parses a line written by slog.TextHandler into a record.
+//
+// The time, level and message are taken from the slog.TimeKey, slog.LevelKey and slog.MessageKey keys and the source
+// location, if present, is added as a "source" attribute formatted as FILE:LINE. Every other key is added as an
+// attribute in the order it appears in the line. Keys containing dots, which slog.TextHandler writes for attributes
+// within groups, are expanded into nested groups (eg: job.name=nightly becomes a "job" group with a "name" attribute).
+// If the line also has a plain key with the same name as a group (eg: both err and err.code), the plain key wins and
+// the dotted keys are kept as they are.
+//
+// Quoted values are always added as strings. Unquoted values are added as the first type they can be parsed as: a
+// bool, an int64, a float64, a time.Duration (eg: 1.5s), an RFC 3339 time or otherwise a string.
+func ParseText(line []byte) (slog.Record, error) {
+	return parseKeyValues(line, nil)
+}
+
+// parseKeyValues parses a line of key/value pairs into a record, renaming any keys found in aliases.
+//
+// Aliases are only used if the line does not also contain the key they are an alias for.
+func parseKeyValues(line []byte, aliases map[string]string) (slog.Record, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return slog.Record{}, ErrEmptyLine
+	}
+	pairs, err := scanLogfmt(string(line))
+	if err != nil {
+		return slog.Record{}, fmt.Errorf("invalid log line: %w", err)
+	}
+
+	if aliases != nil {
+		present := map[string]bool{}
+		for _, p := range pairs {
+			present[p.key] = true
+		}
+		for i, p := range pairs {
+			if key, ok := aliases[p.key]; ok && !present[key] {
+				pairs[i].key = key
+				present[key] = true
+			}
+		}
+	}
+
+	attrs := make([]slog.Attr, 0, len(pairs))
+	for _, p := range pairs {
+		value := slog.StringValue(p.value)
+		if !p.quoted {
+			value = typedValue(p.value)
+		}
+		attrs = append(attrs, slog.Attr{Key: p.key, Value: value})
+	}
+	return buildRecord(expandGroups(attrs))
+}
+
+// scanLogfmt splits a line into its key/value pairs.
+func scanLogfmt(line string) ([]logfmtPair, error) {
+	pairs := []logfmtPair{}
+	for i := 0; i < len(line); {
+		// skip whitespace between pairs
+		if line[i] == ' ' || line[i] == '\t' {
+			i++
+			continue
+		}
+
+		// read the key, which slog.TextHandler quotes if it contains spaces or special characters
+		var p logfmtPair
+		if line[i] == '"' {
+			key, end, err := scanQuoted(line, i)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted key at offset %d: %w", i, err)
+			}
+			p.key, i = key, end
+		} else {
+			start := i
+			for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '\t' {
+				if line[i] == '"' {
+					return nil, fmt.Errorf("unexpected quote in key at offset %d", i)
+				}
+				i++
+			}
+			p.key = line[start:i]
+		}
+		if i >= len(line) || line[i] != '=' {
+			p.value = "true"
+			pairs = append(pairs, p)
+			continue
+		}
+		if p.key == "" {
+			return nil, fmt.Errorf("missing key at offset %d", i)
+		}
+		i++
+
+		// read the value
+		if i < len(line) && line[i] == '"' {
+			value, end, err := scanQuoted(line, i)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for key %q: %w", p.key, err)
+			}
+			p.quoted, p.value, i = true, value, end
+		} else {
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			p.value = line[start:i]
+		}
+		pairs = append(pairs, p)
+	}
+	if len(pairs) == 0 {
+		return nil, errors.New("no key/value pairs found")
+	}
+	return pairs, nil
+}
+
+// scanQuoted unquotes the Go-style quoted string starting at the given offset in the line.
+//
+// The unquoted string and the offset just after the closing quote are returned.
+func scanQuoted(line string, start int) (string, int, error) {
+	end := start + 1
+	for end < len(line) && line[end] != '"' {
+		if line[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= len(line) {
+		return "", 0, errors.New("missing closing quote")
+	}
+	s, err := strconv.Unquote(line[start : end+1])
+	if err != nil {
+		return "", 0, err
+	}
+	return s, end + 1, nil
+}
+
+// typedValue converts an unquoted value into the first type it can be parsed as.
+func typedValue(s string) slog.Value {
+	switch s {
+	case "true":
+		return slog.BoolValue(true)
+	case "false":
+		return slog.BoolValue(false)
+	case "":
+		return slog.StringValue(s)
+	}
+
+	// only values starting like a number are parsed as one, so that values like "NaN" and "Inf" remain strings
+	if c := strings.TrimLeft(s, "+-"); c != "" && c[0] >= '0' && c[0] <= '9' {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return slog.Int64Value(n)
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return slog.Float64Value(f)
+		}
+		if d, err := time.ParseDuration(s); err == nil {
+			return slog.DurationValue(d)
+		}
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return slog.TimeValue(t)
+		}
+	}
+	return slog.StringValue(s)
+}
+
+// expandGroups expands attributes whose keys contain dots into nested groups.
+//
+// Groups are added at the position of their first attribute, so the order of the attributes is kept. If the line also
+// has a plain attribute with the same name as a group (eg: both "x" and "x.y"), the plain attribute wins and the
+// attributes which would belong to the group are kept with their full, dotted keys so that keys are not duplicated.
+func expandGroups(attrs []slog.Attr) []slog.Attr {
+	plain := map[string]bool{}
+	for _, a := range attrs {
+		if group, key, ok := strings.Cut(a.Key, "."); !ok || group == "" || key == "" {
+			plain[a.Key] = true
+		}
+	}
+
+	expanded := []slog.Attr{}
+	groups := map[string]int{}
+	nested := map[string][]slog.Attr{}
+	for _, a := range attrs {
+		group, key, ok := strings.Cut(a.Key, ".")
+		if !ok || group == "" || key == "" || plain[group] {
+			expanded = append(expanded, a)
+			continue
+		}
+		if _, found := groups[group]; !found {
+			groups[group] = len(expanded)
+			expanded = append(expanded, slog.Attr{Key: group})
+		}
+		nested[group] = append(nested[group], slog.Attr{Key: key, Value: a.Value})
+	}
+	for group, i := range groups {
+		expanded[i].Value = slog.GroupValue(expandGroups(nested[group])...)
+	}
+	return expanded
+}
@@ -0,0 +1,132 @@
+package logparse_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.innotegrity.dev/slogx-slack/logparse"
+)
+
+func TestParseTextRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{AddSource: true}))
+	logger.WithGroup("job").Error("backup failed",
+		slog.String("name", "nightly run"),
+		slog.Int("attempt", 2),
+		slog.Float64("ratio", 0.25),
+		slog.Bool("retry", false),
+		slog.Duration("elapsed", 1500*time.Millisecond),
+		slog.Time("started", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		slog.Group("disk", slog.String("device", "/dev/sda1")),
+		slog.String("weird key", "x"),
+	)
+
+	r, err := logparse.ParseText(buf.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse line: %s", err.Error())
+	}
+	if r.Message != "backup failed" || r.Level != slog.LevelError || r.Time.IsZero() {
+		t.Errorf("unexpected built-in fields: %s %s %s", r.Time, r.Level, r.Message)
+	}
+
+	var job slog.Value
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "job" {
+			job = a.Value
+		}
+		return true
+	})
+	if job.Kind() != slog.KindGroup {
+		t.Fatalf("expected job to be a group, got %v", job)
+	}
+	kinds := map[string]slog.Kind{
+		"name":      slog.KindString,
+		"attempt":   slog.KindInt64,
+		"ratio":     slog.KindFloat64,
+		"retry":     slog.KindBool,
+		"elapsed":   slog.KindDuration,
+		"started":   slog.KindTime,
+		"disk":      slog.KindGroup,
+		"weird key": slog.KindString,
+	}
+	attrs := job.Group()
+	if len(attrs) != len(kinds) {
+		t.Fatalf("expected %d attributes in the job group, got %v", len(kinds), attrs)
+	}
+	for _, a := range attrs {
+		if a.Value.Kind() != kinds[a.Key] {
+			t.Errorf("expected %s to be a %s, got %s", a.Key, kinds[a.Key], a.Value.Kind())
+		}
+	}
+	if attrs[0].Value.String() != "nightly run" {
+		t.Errorf("expected the quoted value to be unquoted, got %q", attrs[0].Value.String())
+	}
+}
+
+func TestParseLogfmt(t *testing.T) {
+	r, err := logparse.ParseLogfmt([]byte(`ts=2024-01-02T03:04:05Z lvl=warn message="disk \"sda\" slow" cached ` +
+		`code=007x count=-3`))
+	if err != nil {
+		t.Fatalf("failed to parse line: %s", err.Error())
+	}
+	if r.Level != slog.LevelWarn || r.Message != `disk "sda" slow` || r.Time.Year() != 2024 {
+		t.Errorf("unexpected built-in fields: %s %s %s", r.Time, r.Level, r.Message)
+	}
+	expected := map[string]slog.Value{
+		"cached": slog.BoolValue(true),
+		"code":   slog.StringValue("007x"),
+		"count":  slog.Int64Value(-3),
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if !a.Value.Equal(expected[a.Key]) {
+			t.Errorf("expected %s to be %v, got %v", a.Key, expected[a.Key], a.Value)
+		}
+		delete(expected, a.Key)
+		return true
+	})
+	if len(expected) > 0 {
+		t.Errorf("missing attributes: %v", expected)
+	}
+
+	// aliases are not used when the line also contains the key they are an alias for
+	r, err = logparse.ParseLogfmt([]byte(`msg=real message=alias`))
+	if err != nil {
+		t.Fatalf("failed to parse line: %s", err.Error())
+	}
+	if r.Message != "real" || r.NumAttrs() != 1 {
+		t.Errorf("expected the alias to be kept as an attribute, got %q with %d attributes", r.Message, r.NumAttrs())
+	}
+
+	for _, line := range []string{`msg="unterminated`, `=value`, `level=LOUD`} {
+		if _, err := logparse.ParseLogfmt([]byte(line)); err == nil {
+			t.Errorf("expected an error for %q", line)
+		}
+	}
+}
+
+func TestParseLogfmtPlainAttrWinsOverGroup(t *testing.T) {
+	r, err := logparse.ParseLogfmt([]byte(`msg=failed err=timeout err.code=504 req.id=7 err.retry=true`))
+	if err != nil {
+		t.Fatalf("failed to parse line: %s", err.Error())
+	}
+	keys := []string{}
+	values := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		keys = append(keys, a.Key)
+		values[a.Key] = a.Value
+		return true
+	})
+	expected := []string{"err", "err.code", "req", "err.retry"}
+	if strings.Join(keys, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected attributes %v, got %v", expected, keys)
+	}
+	if values["err"].String() != "timeout" || !values["err.code"].Equal(slog.Int64Value(504)) {
+		t.Errorf("expected the plain attribute and the dotted attributes to be kept, got %v", values)
+	}
+	if values["req"].Kind() != slog.KindGroup {
+		t.Errorf("expected other dotted keys to still be expanded into groups, got %s", values["req"].Kind())
+	}
+}
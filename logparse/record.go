@@ -0,0 +1,81 @@
+package logparse
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.innotegrity.dev/slogx-slack/internal/levels"
+)
+
+// ErrEmptyLine is returned when attempting to parse a line which is empty or only contains whitespace.
+var ErrEmptyLine = errors.New("line is empty")
+
+// ParseFn is a function which parses a single line of log output into a record.
+type ParseFn func(line []byte) (slog.Record, error)
+
+// buildRecord creates a record from the attributes of a parsed line, extracting the built-in fields.
+func buildRecord(attrs []slog.Attr) (slog.Record, error) {
+	var t time.Time
+	level := slog.LevelInfo
+	msg := ""
+	recordAttrs := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		switch a.Key {
+		case slog.TimeKey:
+			switch a.Value.Kind() {
+			case slog.KindTime:
+				t = a.Value.Time()
+			case slog.KindString:
+				parsed, err := time.Parse(time.RFC3339Nano, a.Value.String())
+				if err != nil {
+					return slog.Record{}, fmt.Errorf("invalid time: %w", err)
+				}
+				t = parsed
+			default:
+				recordAttrs = append(recordAttrs, a)
+			}
+		case slog.LevelKey:
+			l, err := levels.Parse(a.Value.String())
+			if err != nil {
+				return slog.Record{}, fmt.Errorf("invalid level: %w", err)
+			}
+			level = l
+		case slog.MessageKey:
+			msg = a.Value.String()
+		case slog.SourceKey:
+			recordAttrs = append(recordAttrs, sourceAttr(a))
+		default:
+			recordAttrs = append(recordAttrs, a)
+		}
+	}
+	r := slog.NewRecord(t, level, msg, 0)
+	r.AddAttrs(recordAttrs...)
+	return r, nil
+}
+
+// sourceAttr converts a source attribute written by a handler into a FILE:LINE string attribute.
+//
+// If the attribute is not a group containing a file, it is returned unchanged.
+func sourceAttr(a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup {
+		return a
+	}
+	file, line := "", ""
+	for _, ga := range a.Value.Group() {
+		switch ga.Key {
+		case "file":
+			file = ga.Value.String()
+		case "line":
+			line = ga.Value.String()
+		}
+	}
+	if file == "" {
+		return a
+	}
+	if line != "" {
+		file += ":" + line
+	}
+	return slog.String(slog.SourceKey, file)
+}
@@ -0,0 +1,121 @@
+package slogxslack
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// messageOverridesContext can be used to retrieve the per-call message overrides from the context.
+type messageOverridesContext struct{}
+
+// mentionIDRegexp matches the ID of a Slack user or user group.
+var mentionIDRegexp = regexp.MustCompile(`^[UWS][A-Z0-9]{2,}$`)
+
+// MessageOverrides holds the per-call overrides carried by a context.
+//
+// Overrides are added to a context using WithChannel(), WithThread(), WithMentions() and WithSuppress() and are read
+// by the handler and formatter from the context passed to the logger's context functions (eg: InfoContext()).
+type MessageOverrides struct {
+	// Channel is the channel the message is posted to instead of the webhook's default channel.
+	Channel string
+
+	// Mentions are the users, user groups and special mentions added to the message.
+	Mentions []string
+
+	// Suppress indicates the record should not be posted at all.
+	Suppress bool
+
+	// ThreadTimestamp is the timestamp of the message the record is posted as a thread reply to.
+	ThreadTimestamp string
+}
+
+// GetMessageOverridesFromContext retrieves the per-call overrides from the context.
+//
+// If no overrides are set in the context, an empty set of overrides is returned.
+func GetMessageOverridesFromContext(ctx context.Context) MessageOverrides {
+	if ctx == nil {
+		return MessageOverrides{}
+	}
+	if o, ok := ctx.Value(messageOverridesContext{}).(MessageOverrides); ok {
+		return o
+	}
+	return MessageOverrides{}
+}
+
+// WithChannel returns a new context which posts records to the given channel rather than the webhook's default
+// channel.
+//
+// Slack only honors the channel of a webhook message for legacy incoming webhooks; webhooks created by Slack apps
+// always post to the channel they were created for. Records belonging to an incident ignore the override and are
+// always posted to the incident's channel.
+func WithChannel(ctx context.Context, channel string) context.Context {
+	o := GetMessageOverridesFromContext(ctx)
+	o.Channel = channel
+	return context.WithValue(ctx, messageOverridesContext{}, o)
+}
+
+// WithMentions returns a new context which adds the given mentions to records.
+//
+// Mentions may be user IDs (eg: U012AB3CD), user group IDs (eg: S012AB3CD), "here", "channel", "everyone" or text
+// already using Slack's mention markup (eg: <@U012AB3CD>). Mentions are added to any mentions already in the context.
+// They are added to the message by the default formatter; custom formatters can read them using
+// GetMessageOverridesFromContext().
+func WithMentions(ctx context.Context, mentions ...string) context.Context {
+	o := GetMessageOverridesFromContext(ctx)
+	o.Mentions = append(append([]string{}, o.Mentions...), mentions...)
+	return context.WithValue(ctx, messageOverridesContext{}, o)
+}
+
+// WithSuppress returns a new context which stops records from being posted.
+func WithSuppress(ctx context.Context) context.Context {
+	o := GetMessageOverridesFromContext(ctx)
+	o.Suppress = true
+	return context.WithValue(ctx, messageOverridesContext{}, o)
+}
+
+// WithThread returns a new context which posts records as replies to the thread of the message with the given
+// timestamp.
+//
+// Records belonging to an incident ignore the override and are always posted as replies to the incident's thread.
+func WithThread(ctx context.Context, ts string) context.Context {
+	o := GetMessageOverridesFromContext(ctx)
+	o.ThreadTimestamp = ts
+	return context.WithValue(ctx, messageOverridesContext{}, o)
+}
+
+// apply sets the channel and thread of the message from the overrides.
+func (o MessageOverrides) apply(message *slack.WebhookMessage) {
+	if o.Channel != "" {
+		message.Channel = o.Channel
+	}
+	if o.ThreadTimestamp != "" {
+		message.ThreadTimestamp = o.ThreadTimestamp
+	}
+}
+
+// mentionText returns the mentions formatted using Slack's mention markup.
+func (o MessageOverrides) mentionText() string {
+	mentions := []string{}
+	for _, m := range o.Mentions {
+		m = strings.TrimSpace(m)
+		name := strings.TrimPrefix(m, "@")
+		switch {
+		case m == "":
+			continue
+		case strings.HasPrefix(m, "<"):
+			mentions = append(mentions, m)
+		case name == "here" || name == "channel" || name == "everyone":
+			mentions = append(mentions, "<!"+name+">")
+		case mentionIDRegexp.MatchString(name) && name[0] == 'S':
+			mentions = append(mentions, "<!subteam^"+name+">")
+		case mentionIDRegexp.MatchString(name):
+			mentions = append(mentions, "<@"+name+">")
+		default:
+			mentions = append(mentions, m)
+		}
+	}
+	return strings.Join(mentions, " ")
+}
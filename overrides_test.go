@@ -0,0 +1,50 @@
+package slogxslack_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestContextOverrides(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler)
+
+	ctx := slogxslack.WithChannel(context.Background(), "#ops")
+	ctx = slogxslack.WithThread(ctx, "1700000000.000100")
+	ctx = slogxslack.WithMentions(ctx, "U012AB3CD", "@here", "S012AB3CD")
+	logger.ErrorContext(ctx, "disk full")
+	logger.ErrorContext(slogxslack.WithSuppress(ctx), "suppressed")
+	logger.Error("no overrides")
+
+	messages := server.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	m := messages[0]
+	if m.Channel != "#ops" || m.ThreadTimestamp != "1700000000.000100" {
+		t.Errorf("expected the channel and thread to be overridden, got %q and %q", m.Channel, m.ThreadTimestamp)
+	}
+	mentions := "<@U012AB3CD> <!here> <!subteam^S012AB3CD>"
+	slacktest.AssertContainsText(t, m, mentions)
+	if m.Text != mentions+" disk full" {
+		t.Errorf("expected the notification text to include the mentions, got %q", m.Text)
+	}
+	if m := messages[1]; m.Channel != "" || m.ThreadTimestamp != "" || m.ContainsText("<@U012AB3CD>") {
+		t.Error("expected the overrides to only apply to records logged with the context")
+	}
+	if handler.Enabled(slogxslack.WithSuppress(context.Background()), slog.LevelError) {
+		t.Error("expected no levels to be enabled for a suppressed context")
+	}
+}
@@ -0,0 +1,173 @@
+package slogxslack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"go.innotegrity.dev/slogx/formatter"
+)
+
+const (
+	// RedactedValue is the value output in place of an attribute's value by the "redact" attribute formatter.
+	RedactedValue = "********"
+)
+
+// formatterRegistry holds the named formatting functions which can be referenced from a configuration.
+type formatterRegistry struct {
+	attr   map[string]formatter.FormatAttrFn
+	level  map[string]formatter.FormatLevelValueFn
+	mu     sync.RWMutex
+	source map[string]formatter.FormatSourceValueFn
+	time   map[string]formatter.FormatTimeValueFn
+}
+
+// registry is the global registry of named formatting functions.
+var registry = &formatterRegistry{
+	attr: map[string]formatter.FormatAttrFn{
+		"redact": formatAttrRedact,
+	},
+	level: map[string]formatter.FormatLevelValueFn{
+		"default": formatSlackMessageLevelDefault,
+		"plain":   formatter.FormatLevelValueDefault,
+	},
+	source: map[string]formatter.FormatSourceValueFn{
+		"default": formatter.FormatSourceValueDefault,
+	},
+	time: map[string]formatter.FormatTimeValueFn{
+		"default":     formatSlackMessageTimeDefault,
+		"kitchen":     formatTimeLayout(time.Kitchen, false),
+		"rfc3339":     formatTimeLayout(time.RFC3339, false),
+		"rfc3339_utc": formatTimeLayout(time.RFC3339, true),
+	},
+}
+
+// RegisterAttrFormatter registers a named attribute formatting function which can be referenced from a configuration.
+//
+// Registering a function with the same name as an existing function replaces it. The following functions are
+// built-in:
+//
+//   - redact: replaces the attribute's value with RedactedValue
+func RegisterAttrFormatter(name string, fn formatter.FormatAttrFn) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.attr[name] = fn
+}
+
+// RegisterLevelFormatter registers a named level formatting function which can be referenced from a configuration.
+//
+// Registering a function with the same name as an existing function replaces it. The following functions are
+// built-in:
+//
+//   - default: formats the level using the emoji and label from the formatter's level registry
+//   - plain: formats the level using FormatLevelValueDefault()
+func RegisterLevelFormatter(name string, fn formatter.FormatLevelValueFn) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.level[name] = fn
+}
+
+// RegisterSourceFormatter registers a named source formatting function which can be referenced from a configuration.
+//
+// Registering a function with the same name as an existing function replaces it. The following functions are
+// built-in:
+//
+//   - default: formats the source location using FormatSourceValueDefault()
+func RegisterSourceFormatter(name string, fn formatter.FormatSourceValueFn) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.source[name] = fn
+}
+
+// RegisterTimeFormatter registers a named time formatting function which can be referenced from a configuration.
+//
+// Registering a function with the same name as an existing function replaces it. The following functions are
+// built-in:
+//
+//   - default: formats the time in the local timezone using the "03:04:05PM MST" layout
+//   - kitchen: formats the time in the local timezone using the time.Kitchen layout
+//   - rfc3339: formats the time in the local timezone using the time.RFC3339 layout
+//   - rfc3339_utc: formats the time in UTC using the time.RFC3339 layout
+func RegisterTimeFormatter(name string, fn formatter.FormatTimeValueFn) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.time[name] = fn
+}
+
+// attrFormatter returns the named attribute formatting function.
+func (r *formatterRegistry) attrFormatter(name string) (formatter.FormatAttrFn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fn, ok := r.attr[name]; ok {
+		return fn, nil
+	}
+	return nil, unknownFormatterError("attribute", name, r.attr)
+}
+
+// levelFormatter returns the named level formatting function.
+func (r *formatterRegistry) levelFormatter(name string) (formatter.FormatLevelValueFn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fn, ok := r.level[name]; ok {
+		return fn, nil
+	}
+	return nil, unknownFormatterError("level", name, r.level)
+}
+
+// sourceFormatter returns the named source formatting function.
+func (r *formatterRegistry) sourceFormatter(name string) (formatter.FormatSourceValueFn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fn, ok := r.source[name]; ok {
+		return fn, nil
+	}
+	return nil, unknownFormatterError("source", name, r.source)
+}
+
+// timeFormatter returns the named time formatting function.
+func (r *formatterRegistry) timeFormatter(name string) (formatter.FormatTimeValueFn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fn, ok := r.time[name]; ok {
+		return fn, nil
+	}
+	return nil, unknownFormatterError("time", name, r.time)
+}
+
+// unknownFormatterError returns an error listing the available formatters of the given kind.
+func unknownFormatterError[T any](kind, name string, fns map[string]T) error {
+	names := []string{}
+	for n := range fns {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown %s formatter %q (available: %v)", kind, name, names)
+}
+
+// formatAttrRedact replaces the attribute's value with RedactedValue.
+func formatAttrRedact(ctx context.Context, level slog.Leveler, group, key string, value slog.Value) (string, slog.Value,
+	error) {
+
+	if group != "" {
+		key = group + "." + key
+	}
+	return key, slog.StringValue(RedactedValue), nil
+}
+
+// formatSlackMessageTimeDefault formats the time in the local timezone using the "03:04:05PM MST" layout.
+func formatSlackMessageTimeDefault(ctx context.Context, level slog.Leveler, t time.Time) (string, error) {
+	return t.Local().Format("03:04:05PM MST"), nil
+}
+
+// formatTimeLayout returns a time formatting function which uses the given layout.
+func formatTimeLayout(layout string, utc bool) formatter.FormatTimeValueFn {
+	return func(ctx context.Context, level slog.Leveler, t time.Time) (string, error) {
+		if utc {
+			return t.UTC().Format(layout), nil
+		}
+		return t.Local().Format(layout), nil
+	}
+}
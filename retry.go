@@ -0,0 +1,174 @@
+package slogxslack
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// DefaultRetryBackoff is the default amount of time to wait before the first retry.
+	DefaultRetryBackoff = time.Second
+
+	// DefaultRetryMaxBackoff is the default maximum amount of time to wait between retries.
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy determines whether and how often the handler retries posting a message to the webhook when the post
+// fails.
+//
+// Posts are retried when Slack rate limits the request, when it responds with a server error (HTTP 5xx) or when the
+// request could not be sent at all. Any other error, such as an invalid webhook URL, is returned immediately.
+type RetryPolicy struct {
+	// Backoff is the amount of time to wait before the first retry, which is doubled after each retry.
+	//
+	// If Slack rate limits the request, the handler waits for the longer of this and the time given by Slack's
+	// Retry-After header instead. If zero, DefaultRetryBackoff is used.
+	Backoff time.Duration
+
+	// MaxAttempts is the maximum number of times to attempt posting a message, including the first attempt.
+	//
+	// If less than 2, messages are not retried.
+	MaxAttempts int
+
+	// MaxBackoff is the maximum amount of time to wait between retries.
+	//
+	// If zero, DefaultRetryMaxBackoff is used.
+	MaxBackoff time.Duration
+}
+
+// validate checks the policy for errors.
+func (p RetryPolicy) validate() error {
+	if p.Backoff < 0 || p.MaxBackoff < 0 {
+		return errors.New("retry backoff cannot be negative")
+	}
+	if p.MaxAttempts < 0 {
+		return errors.New("retry attempts cannot be negative")
+	}
+	return nil
+}
+
+// do calls fn until it succeeds, it returns an error which cannot be retried or the maximum number of attempts is
+// reached, waiting between attempts according to the policy.
+//
+// If the receiver is nil, fn is only called once. If the context is done while waiting, the last error is returned.
+func (p *RetryPolicy) do(ctx context.Context, fn func() error) error {
+	err := fn()
+	if p == nil {
+		return err
+	}
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+	for attempt := 1; attempt < p.MaxAttempts && retryable(err); attempt++ {
+		wait := min(backoff, maxBackoff)
+		var rateLimitErr *slack.RateLimitedError
+		if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > wait {
+			wait = rateLimitErr.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = fn()
+		backoff *= 2
+	}
+	return err
+}
+
+// retryable determines whether or not posting a message which failed with the given error should be retried.
+func retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var rateLimitErr *slack.RateLimitedError
+	if errors.As(err, &rateLimitErr) {
+		return true
+	}
+	var statusErr slack.StatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RateLimit limits the number of messages the handler posts to Slack.
+//
+// Slack only accepts around one message per second for each webhook, so bursts of records are better dropped than
+// queued behind Slack's own rate limit.
+type RateLimit struct {
+	// Interval is the period of time in which at most Messages messages are posted.
+	//
+	// This is a required option.
+	Interval time.Duration
+
+	// Messages is the maximum number of messages posted within any Interval.
+	//
+	// Messages are allowed to be posted in a burst, up to this number, after which the allowance is replenished
+	// evenly over the interval. This is a required option.
+	Messages int
+}
+
+// validate checks the limit for errors.
+func (l RateLimit) validate() error {
+	if l.Messages <= 0 {
+		return errors.New("rate limit messages must be greater than zero")
+	}
+	if l.Interval <= 0 {
+		return errors.New("rate limit interval must be greater than zero")
+	}
+	return nil
+}
+
+// rateLimiter is a token bucket shared by a handler and every handler derived from it.
+type rateLimiter struct {
+	// unexported variables
+	last   time.Time
+	limit  RateLimit
+	mu     sync.Mutex
+	tokens float64
+}
+
+// newRateLimiter creates a new rate limiter which starts with its full allowance.
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	return &rateLimiter{
+		last:   time.Now(),
+		limit:  limit,
+		tokens: float64(limit.Messages),
+	}
+}
+
+// allow determines whether or not another message can be posted now, using up part of the allowance if it can.
+//
+// If the receiver is nil, every message is allowed.
+func (l *rateLimiter) allow(now time.Time) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.After(l.last) {
+		perMessage := float64(l.limit.Interval) / float64(l.limit.Messages)
+		l.tokens = min(float64(l.limit.Messages), l.tokens+float64(now.Sub(l.last))/perMessage)
+		l.last = now
+	}
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
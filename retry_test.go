@@ -0,0 +1,92 @@
+package slogxslack_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Retry: &slogxslack.RetryPolicy{
+			Backoff:     time.Millisecond,
+			MaxAttempts: 3,
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler)
+
+	// server errors and rate limits are retried
+	server.Fail(1, http.StatusServiceUnavailable)
+	server.RateLimit(1, 0)
+	r := slog.NewRecord(time.Now(), slog.LevelError, "retried", 0)
+	if err := handler.Handle(context.Background(), r); err != nil {
+		t.Fatalf("expected the message to be retried, got: %s", err.Error())
+	}
+	if n := len(server.Messages()); n != 1 || server.Rejected() != 2 {
+		t.Errorf("expected 1 message after 2 rejections, got %d after %d", n, server.Rejected())
+	}
+
+	// attempts are limited
+	server.Reset()
+	server.Fail(3, http.StatusBadGateway)
+	logger.Error("exhausted")
+	if n := len(server.Messages()); n != 0 || server.Rejected() != 3 {
+		t.Errorf("expected no messages after 3 rejections, got %d after %d", n, server.Rejected())
+	}
+
+	// client errors are not retried
+	server.Reset()
+	server.Fail(1, http.StatusNotFound)
+	logger.Error("not found")
+	if n := len(server.Messages()); n != 0 || server.Rejected() != 1 {
+		t.Errorf("expected no messages after 1 rejection, got %d after %d", n, server.Rejected())
+	}
+	if stats := handler.Stats(); stats.Delivered != 1 || stats.Failed != 2 {
+		t.Errorf("expected 1 delivered and 2 failed, got %+v", stats)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		RateLimit: &slogxslack.RateLimit{
+			Interval: time.Hour,
+			Messages: 2,
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler).With("app", "billing")
+	for i := 0; i < 3; i++ {
+		logger.Error("over the limit", "i", i)
+	}
+	if n := len(server.Messages()); n != 2 {
+		t.Errorf("expected 2 messages, got %d", n)
+	}
+	if stats := handler.Stats(); stats.Delivered != 2 || stats.Dropped != 1 {
+		t.Errorf("expected 2 delivered and 1 dropped, got %+v", stats)
+	}
+
+	if _, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		RateLimit:  &slogxslack.RateLimit{Messages: 2},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	}); err == nil {
+		t.Error("expected an error for a rate limit without an interval")
+	}
+}
@@ -0,0 +1,232 @@
+package slogxslack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// MessageRendering determines how the formatter renders the message of a record.
+type MessageRendering string
+
+const (
+	// MessageRenderingEscaped renders the message as mrkdwn text with any characters Slack would interpret as markup
+	// escaped, so that the message is displayed exactly as it was logged.
+	MessageRenderingEscaped MessageRendering = "escaped"
+
+	// MessageRenderingMrkdwn renders the message as mrkdwn text without escaping it, so that any markup in the message
+	// is formatted by Slack.
+	MessageRenderingMrkdwn MessageRendering = "mrkdwn"
+
+	// MessageRenderingRichText renders the message as a rich_text block.
+	//
+	// Single-line messages are rendered as a rich text section. For multi-line messages, the first line is rendered as
+	// a section and the remaining lines are rendered as preformatted text, except for runs of lines starting with "> ",
+	// which are rendered as a quote, and runs of lines starting with "- ", "* " or "• ", which are rendered as a
+	// bulleted list. Messages, or the lines after the first line, which look like JSON or a diff are rendered entirely
+	// as preformatted text. HTTP and HTTPS URLs outside of JSON and diffs are rendered as links.
+	MessageRenderingRichText MessageRendering = "rich_text"
+)
+
+// zeroWidthSpace is inserted around mrkdwn formatting characters to stop Slack from treating them as markup.
+const zeroWidthSpace = "\u200b"
+
+var (
+	// diffLineRegexp matches the header lines of a unified diff.
+	diffLineRegexp = regexp.MustCompile(`^(?:@@ .* @@|diff --git |--- \S|\+\+\+ \S)`)
+
+	// mrkdwnEscaper escapes the characters Slack interprets as markup in mrkdwn text.
+	mrkdwnEscaper = strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		"*", zeroWidthSpace+"*"+zeroWidthSpace,
+		"_", zeroWidthSpace+"_"+zeroWidthSpace,
+		"~", zeroWidthSpace+"~"+zeroWidthSpace,
+		"`", zeroWidthSpace+"`"+zeroWidthSpace,
+	)
+
+	// richTextURLRegexp matches the HTTP and HTTPS URLs linked in rich text.
+	richTextURLRegexp = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+)
+
+// EscapeMrkdwn escapes any characters in the text which Slack would interpret as mrkdwn markup.
+//
+// The &, < and > characters are replaced by HTML entities, which stops Slack from creating links and mentions. Slack
+// does not support escaping the *, _, ~ and ` formatting characters, so zero-width spaces are added around them
+// instead, which stops Slack from formatting the text without changing how it is displayed.
+func EscapeMrkdwn(text string) string {
+	return mrkdwnEscaper.Replace(text)
+}
+
+// validate determines whether or not the rendering is known.
+func (m MessageRendering) validate() error {
+	switch m {
+	case "", MessageRenderingEscaped, MessageRenderingMrkdwn, MessageRenderingRichText:
+		return nil
+	}
+	return fmt.Errorf("unknown message rendering %q", m)
+}
+
+// mrkdwn returns the text to use for the message within mrkdwn text.
+//
+// The message is only left unescaped when rendering it as mrkdwn.
+func (m MessageRendering) mrkdwn(msg string) string {
+	if m == MessageRenderingMrkdwn {
+		return msg
+	}
+	return EscapeMrkdwn(msg)
+}
+
+// block returns the block displaying the message.
+func (m MessageRendering) block(msg string) slack.Block {
+	if m == MessageRenderingRichText {
+		return renderRichText(msg)
+	}
+	return slack.SectionBlock{
+		Type: slack.MBTSection,
+		Text: &slack.TextBlockObject{
+			Type: slack.MarkdownType,
+			Text: m.mrkdwn(msg),
+		},
+	}
+}
+
+// richTextList is a rich text list element, which is not provided by the Slack package.
+type richTextList struct {
+	Elements []slack.RichTextElement   `json:"elements"`
+	Style    string                    `json:"style"`
+	Type     slack.RichTextElementType `json:"type"`
+}
+
+// RichTextElementType returns the type of the element.
+func (l richTextList) RichTextElementType() slack.RichTextElementType {
+	return l.Type
+}
+
+// renderRichText renders the message as a rich_text block.
+func renderRichText(msg string) *slack.RichTextBlock {
+	msg = strings.TrimRight(msg, "\r\n")
+	if looksLikeCode(msg) {
+		return slack.NewRichTextBlock("", richTextPreformatted(msg))
+	}
+	lines := strings.Split(msg, "\n")
+	elements := []slack.RichTextElement{slack.NewRichTextSection(richTextLinked(lines[0])...)}
+	rest := strings.Join(lines[1:], "\n")
+	if strings.TrimSpace(rest) == "" {
+		return slack.NewRichTextBlock("", elements...)
+	}
+	if looksLikeCode(rest) {
+		return slack.NewRichTextBlock("", append(elements, richTextPreformatted(rest))...)
+	}
+
+	// group the remaining lines into runs of quotes, list items and preformatted text
+	kind, run := "", []string{}
+	flush := func() {
+		switch kind {
+		case "quote":
+			elements = append(elements, &slack.RichTextSection{
+				Type:     slack.RTEQuote,
+				Elements: richTextLinked(strings.Join(run, "\n")),
+			})
+		case "list":
+			list := richTextList{Elements: []slack.RichTextElement{}, Style: "bullet", Type: slack.RTEList}
+			for _, item := range run {
+				list.Elements = append(list.Elements, slack.NewRichTextSection(richTextLinked(item)...))
+			}
+			elements = append(elements, list)
+		case "preformatted":
+			if text := strings.Trim(strings.Join(run, "\n"), "\r\n"); text != "" {
+				elements = append(elements, &slack.RichTextSection{
+					Type:     slack.RTEPreformatted,
+					Elements: richTextLinked(text),
+				})
+			}
+		}
+		kind, run = "", []string{}
+	}
+	for _, line := range lines[1:] {
+		lineKind, text := "preformatted", line
+		if quote, ok := strings.CutPrefix(line, ">"); ok {
+			lineKind, text = "quote", strings.TrimPrefix(quote, " ")
+		} else {
+			for _, bullet := range []string{"- ", "* ", "• "} {
+				if item, ok := strings.CutPrefix(strings.TrimLeft(line, " "), bullet); ok {
+					lineKind, text = "list", item
+					break
+				}
+			}
+		}
+		if strings.TrimSpace(line) == "" && kind != "preformatted" {
+			flush()
+			continue
+		}
+		if lineKind != kind {
+			flush()
+			kind = lineKind
+		}
+		run = append(run, text)
+	}
+	flush()
+	return slack.NewRichTextBlock("", elements...)
+}
+
+// richTextLinked splits the text into text elements and link elements for any URLs in the text.
+//
+// Trailing punctuation is not considered part of a URL.
+func richTextLinked(text string) []slack.RichTextSectionElement {
+	elements := []slack.RichTextSectionElement{}
+	start := 0
+	for _, loc := range richTextURLRegexp.FindAllStringIndex(text, -1) {
+		url := strings.TrimRight(text[loc[0]:loc[1]], ".,;:!?)]}")
+		if loc[0] > start {
+			elements = append(elements, slack.NewRichTextSectionTextElement(text[start:loc[0]], nil))
+		}
+		elements = append(elements, slack.NewRichTextSectionLinkElement(url, url, nil))
+		start = loc[0] + len(url)
+	}
+	if start < len(text) || len(elements) == 0 {
+		elements = append(elements, slack.NewRichTextSectionTextElement(text[start:], nil))
+	}
+	return elements
+}
+
+// richTextPreformatted returns a preformatted element containing the text without any links.
+func richTextPreformatted(text string) *slack.RichTextSection {
+	return &slack.RichTextSection{
+		Type:     slack.RTEPreformatted,
+		Elements: []slack.RichTextSectionElement{slack.NewRichTextSectionTextElement(text, nil)},
+	}
+}
+
+// looksLikeCode determines whether or not the text looks like JSON or a unified diff.
+func looksLikeCode(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return false
+	}
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)) {
+		return true
+	}
+
+	// diffs need either a diff header line or both added and removed lines with every line marked as changed or
+	// unchanged
+	added, removed, other := false, false, false
+	for _, line := range strings.Split(text, "\n") {
+		if diffLineRegexp.MatchString(line) {
+			return true
+		}
+		switch {
+		case strings.HasPrefix(line, "+"):
+			added = true
+		case strings.HasPrefix(line, "-"):
+			removed = true
+		case line != "" && !strings.HasPrefix(line, " "):
+			other = true
+		}
+	}
+	return added && removed && !other
+}
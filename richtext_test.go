@@ -0,0 +1,108 @@
+package slogxslack_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+func TestEscapeMrkdwn(t *testing.T) {
+	escaped := slogxslack.EscapeMrkdwn("a < b && *c* <!here> _d_ ~e~ `f`")
+	for _, s := range []string{"<", ">", "&&", "*c*", "_d_", "~e~", "`f`"} {
+		if strings.Contains(escaped, s) {
+			t.Errorf("expected %q to be escaped in %q", s, escaped)
+		}
+	}
+	if !strings.Contains(escaped, "&lt;!here&gt;") {
+		t.Errorf("expected brackets to be replaced by entities, got %q", escaped)
+	}
+	visible := strings.ReplaceAll(escaped, "\u200b", "")
+	if visible != "a &lt; b &amp;&amp; *c* &lt;!here&gt; _d_ ~e~ `f`" {
+		t.Errorf("expected only zero-width spaces to be added around formatting characters, got %q", visible)
+	}
+}
+
+func TestMessageRendering(t *testing.T) {
+	msg := "deploy *failed* <!channel>"
+	tests := map[slogxslack.MessageRendering]string{
+		"":                                 slogxslack.EscapeMrkdwn(msg),
+		slogxslack.MessageRenderingEscaped: slogxslack.EscapeMrkdwn(msg),
+		slogxslack.MessageRenderingMrkdwn:  msg,
+	}
+	for rendering, expected := range tests {
+		message := formatMessage(t, rendering, msg)
+		section, ok := message.Blocks.BlockSet[len(message.Blocks.BlockSet)-1].(slack.SectionBlock)
+		if !ok || section.Text.Text != expected {
+			t.Errorf("%q: expected the message to be rendered as %q", rendering, expected)
+		}
+	}
+}
+
+func TestMessageRenderingRichText(t *testing.T) {
+	msg := strings.Join([]string{
+		"deploy failed, see https://example.com/deploys/42.",
+		"> rollback started",
+		"- api",
+		"- worker",
+		"panic: boom",
+		"goroutine 1 [running]:",
+	}, "\n")
+	block := richTextBlock(t, formatMessage(t, slogxslack.MessageRenderingRichText, msg))
+	types := []slack.RichTextElementType{}
+	for _, e := range block.Elements {
+		types = append(types, e.RichTextElementType())
+	}
+	expected := []slack.RichTextElementType{slack.RTESection, slack.RTEQuote, slack.RTEList, slack.RTEPreformatted}
+	if len(types) != len(expected) {
+		t.Fatalf("expected elements %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Fatalf("expected elements %v, got %v", expected, types)
+		}
+	}
+	first := block.Elements[0].(*slack.RichTextSection)
+	if len(first.Elements) != 3 {
+		t.Fatalf("expected the first line to be split around the link, got %d elements", len(first.Elements))
+	}
+	link, ok := first.Elements[1].(*slack.RichTextSectionLinkElement)
+	if !ok || link.URL != "https://example.com/deploys/42" {
+		t.Errorf("expected a link without the trailing period, got %#v", first.Elements[1])
+	}
+
+	// JSON and diffs are shown as code
+	for _, code := range []string{`{"error": "timeout", "url": "https://example.com"}`, "-old\n+new\n same"} {
+		block := richTextBlock(t, formatMessage(t, slogxslack.MessageRenderingRichText, code))
+		if len(block.Elements) != 1 || block.Elements[0].RichTextElementType() != slack.RTEPreformatted {
+			t.Errorf("expected %q to be rendered as preformatted text", code)
+		}
+	}
+}
+
+// formatMessage formats a record with the given message using the given message rendering.
+func formatMessage(t *testing.T, rendering slogxslack.MessageRendering, msg string) *slack.WebhookMessage {
+	t.Helper()
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.MessageRendering = rendering
+	message, err := slogxslack.NewSlackMessageFormatter(opts).FormatRecord(context.Background(), time.Now(),
+		slogx.LevelError, 0, msg, nil)
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	return message
+}
+
+// richTextBlock returns the rich text block holding the message.
+func richTextBlock(t *testing.T, message *slack.WebhookMessage) *slack.RichTextBlock {
+	t.Helper()
+	block, ok := message.Blocks.BlockSet[len(message.Blocks.BlockSet)-1].(*slack.RichTextBlock)
+	if !ok {
+		t.Fatalf("expected the message to be rendered as a rich text block")
+	}
+	return block
+}
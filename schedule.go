@@ -0,0 +1,398 @@
+package slogxslack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"go.innotegrity.dev/slogx"
+)
+
+// MaxHeldSummaryMessages is the maximum number of held record messages included in the summary posted at the end of
+// a schedule window whose action is ScheduleActionHold.
+const MaxHeldSummaryMessages = 10
+
+// ScheduleAction is the action the handler takes on records while a schedule window is active.
+type ScheduleAction string
+
+const (
+	// ScheduleActionHold holds records while the window is active and posts a single summary of them when the window
+	// ends.
+	ScheduleActionHold ScheduleAction = "hold"
+
+	// ScheduleActionRaiseLevel drops records while the window is active.
+	ScheduleActionRaiseLevel ScheduleAction = "raise_level"
+
+	// ScheduleActionRedirect posts records to the window's WebhookURL while the window is active.
+	ScheduleActionRedirect ScheduleAction = "redirect"
+)
+
+// ScheduleWindow describes a period of time, such as planned maintenance, during which the handler treats records
+// differently.
+//
+// A window is either an explicit period of time, using Start and End, or a recurring period of time starting whenever
+// the Cron expression matches and lasting for Duration.
+type ScheduleWindow struct {
+	// Action is the action to take on records while the window is active.
+	//
+	// This is a required setting.
+	Action ScheduleAction `json:"action" yaml:"action"`
+
+	// Cron is a standard 5-field cron expression (minute, hour, day of month, month and day of week) describing when
+	// a recurring window starts.
+	//
+	// If empty, the window is an explicit period of time from Start to End.
+	Cron string `json:"cron,omitempty" yaml:"cron"`
+
+	// Duration is the length of time a recurring window lasts.
+	//
+	// This is a required setting if Cron is set.
+	Duration ConfigDuration `json:"duration,omitempty" yaml:"duration"`
+
+	// End is the time at which an explicit window ends.
+	//
+	// This is a required setting if Cron is empty.
+	End time.Time `json:"end" yaml:"end"`
+
+	// Level is the name of the lowest level which is not affected by the window, as accepted by ParseLevel() (eg:
+	// "fatal" to only allow fatal and panic messages through during maintenance).
+	//
+	// If empty, every record is affected by the window.
+	Level string `json:"level,omitempty" yaml:"level"`
+
+	// Name uniquely identifies the window.
+	//
+	// This is a required setting.
+	Name string `json:"name" yaml:"name"`
+
+	// Start is the time at which an explicit window starts.
+	//
+	// If zero, the window starts immediately.
+	Start time.Time `json:"start" yaml:"start"`
+
+	// Timezone is the name of the IANA timezone in which the Cron expression is evaluated (eg: "America/New_York").
+	//
+	// If empty, the local timezone is used.
+	Timezone string `json:"timezone,omitempty" yaml:"timezone"`
+
+	// WebhookURL is the Slack webhook URL to post records to while a window whose action is ScheduleActionRedirect is
+	// active.
+	WebhookURL Secret `json:"webhook_url" yaml:"webhook_url"`
+}
+
+// scheduleWindow is a validated schedule window.
+type scheduleWindow struct {
+	cron     *cronSchedule
+	hasLevel bool
+	level    slog.Level
+	location *time.Location
+	window   ScheduleWindow
+}
+
+// newScheduleWindow validates the given window.
+func newScheduleWindow(w ScheduleWindow) (*scheduleWindow, error) {
+	if w.Name == "" {
+		return nil, errors.New("schedule window name is required and cannot be empty")
+	}
+	sw := &scheduleWindow{
+		location: time.Local,
+		window:   w,
+	}
+	switch w.Action {
+	case ScheduleActionHold, ScheduleActionRaiseLevel:
+	case ScheduleActionRedirect:
+		if w.WebhookURL.IsZero() {
+			return nil, fmt.Errorf("schedule window '%s' redirects records but has no webhook URL", w.Name)
+		}
+	default:
+		return nil, fmt.Errorf("schedule window '%s' has an unknown action %q", w.Name, w.Action)
+	}
+	if w.Level != "" {
+		level, err := ParseLevel(w.Level)
+		if err != nil {
+			return nil, fmt.Errorf("schedule window '%s' has an invalid level: %w", w.Name, err)
+		}
+		sw.hasLevel, sw.level = true, level
+	}
+	if w.Timezone != "" {
+		loc, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("schedule window '%s' has an invalid timezone: %w", w.Name, err)
+		}
+		sw.location = loc
+	}
+
+	if w.Cron == "" {
+		if w.End.IsZero() || (!w.Start.IsZero() && !w.End.After(w.Start)) {
+			return nil, fmt.Errorf("schedule window '%s' must have an end time after its start time", w.Name)
+		}
+		return sw, nil
+	}
+	if w.Duration <= 0 {
+		return nil, fmt.Errorf("schedule window '%s' must have a positive duration", w.Name)
+	}
+	cron, err := parseCron(w.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("schedule window '%s': %w", w.Name, err)
+	}
+	sw.cron = cron
+	return sw, nil
+}
+
+// affects determines whether or not records with the given level are affected by the window.
+func (w *scheduleWindow) affects(level slog.Level) bool {
+	return !w.hasLevel || level < w.level
+}
+
+// occurrence returns the start and end of the occurrence of the window which contains the given time, if any.
+func (w *scheduleWindow) occurrence(now time.Time) (time.Time, time.Time, bool) {
+	if w.cron == nil {
+		if (w.window.Start.IsZero() || !now.Before(w.window.Start)) && now.Before(w.window.End) {
+			return w.window.Start, w.window.End, true
+		}
+		return time.Time{}, time.Time{}, false
+	}
+
+	d := time.Duration(w.window.Duration)
+	if start, ok := w.cron.prev(now.In(w.location), now.Add(-d)); ok {
+		return start, start.Add(d), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// heldRecords holds a summary of the records held during a single occurrence of a schedule window.
+type heldRecords struct {
+	counts   map[slog.Level]int
+	end      time.Time
+	maxLevel slog.Level
+	messages []string
+	start    time.Time
+	timer    *time.Timer
+	total    int
+	window   string
+}
+
+// record returns a record summarizing the held records.
+func (h *heldRecords) record() slog.Record {
+	r := slog.NewRecord(time.Now(), h.maxLevel,
+		fmt.Sprintf("%d record(s) were held during schedule window '%s'", h.total, h.window), 0)
+
+	levels := make([]slog.Level, 0, len(h.counts))
+	for l := range h.counts {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] > levels[j] })
+	counts := make([]any, 0, len(levels))
+	for _, l := range levels {
+		counts = append(counts, slog.Int(slogx.Level(l).String(), h.counts[l]))
+	}
+
+	attrs := []slog.Attr{slog.String("window", h.window)}
+	if !h.start.IsZero() {
+		attrs = append(attrs, slog.Time("window_start", h.start))
+	}
+	attrs = append(attrs, slog.Time("window_end", h.end), slog.Group("held", counts...))
+	if h.total > len(h.messages) {
+		attrs = append(attrs, slog.Any("messages", append(h.messages,
+			fmt.Sprintf("... and %d more", h.total-len(h.messages)))))
+	} else {
+		attrs = append(attrs, slog.Any("messages", h.messages))
+	}
+	r.AddAttrs(attrs...)
+	return r
+}
+
+// handlerSchedule holds the schedule windows shared by a handler and every handler derived from it.
+type handlerSchedule struct {
+	held     map[string]*heldRecords
+	mu       sync.Mutex
+	released bool
+	windows  []*scheduleWindow
+}
+
+// newHandlerSchedule creates a new schedule from the given windows.
+func newHandlerSchedule(windows []ScheduleWindow) (*handlerSchedule, error) {
+	s := &handlerSchedule{
+		held:    map[string]*heldRecords{},
+		windows: []*scheduleWindow{},
+	}
+	for _, w := range windows {
+		if err := s.set(w); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// hold adds the record to the records held for the given occurrence of the window.
+//
+// If this is the first record held for the occurrence, onEnd is called with the held records once the occurrence
+// ends, unless they have already been released by release(). Once the schedule has been released, no more records
+// are held and false is returned.
+func (s *handlerSchedule) hold(w *scheduleWindow, start, end time.Time, r slog.Record,
+	onEnd func(*heldRecords)) bool {
+
+	key := fmt.Sprintf("%s@%d", w.window.Name, start.UnixNano())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.released {
+		return false
+	}
+	held, ok := s.held[key]
+	if !ok {
+		held = &heldRecords{
+			counts:   map[slog.Level]int{},
+			end:      end,
+			maxLevel: r.Level,
+			messages: []string{},
+			start:    start,
+			window:   w.window.Name,
+		}
+		s.held[key] = held
+		held.timer = time.AfterFunc(time.Until(end), func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if held, ok := s.held[key]; ok {
+				delete(s.held, key)
+				onEnd(held)
+			}
+		})
+	}
+	held.counts[r.Level]++
+	held.total++
+	if r.Level > held.maxLevel {
+		held.maxLevel = r.Level
+	}
+	if len(held.messages) < MaxHeldSummaryMessages {
+		held.messages = append(held.messages, r.Message)
+	}
+	return true
+}
+
+// list returns a copy of the windows in the schedule.
+func (s *handlerSchedule) list() []ScheduleWindow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	windows := make([]ScheduleWindow, 0, len(s.windows))
+	for _, w := range s.windows {
+		windows = append(windows, w.window)
+	}
+	return windows
+}
+
+// match returns the first window which is active at the given time and affects records with the given level.
+func (s *handlerSchedule) match(now time.Time, level slog.Level) (*scheduleWindow, time.Time, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.windows {
+		if !w.affects(level) {
+			continue
+		}
+		if start, end, ok := w.occurrence(now); ok {
+			return w, start, end, true
+		}
+	}
+	return nil, time.Time{}, time.Time{}, false
+}
+
+// remove removes the window with the given name from the schedule, returning whether or not it was found.
+func (s *handlerSchedule) remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.windows {
+		if w.window.Name == name {
+			s.windows = append(s.windows[:i:i], s.windows[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// set validates the window and adds it to the schedule, replacing any existing window with the same name.
+func (s *handlerSchedule) set(w ScheduleWindow) error {
+	sw, err := newScheduleWindow(w)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.windows {
+		if existing.window.Name == w.Name {
+			s.windows[i] = sw
+			return nil
+		}
+	}
+	s.windows = append(s.windows, sw)
+	return nil
+}
+
+// release stops holding records and calls onEnd with every set of held records, stopping their timers.
+//
+// The held records are released in the order their windows end.
+func (s *handlerSchedule) release(onEnd func(*heldRecords)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = true
+	all := make([]*heldRecords, 0, len(s.held))
+	for key, held := range s.held {
+		held.timer.Stop()
+		all = append(all, held)
+		delete(s.held, key)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].end.Before(all[j].end) })
+	for _, held := range all {
+		onEnd(held)
+	}
+}
+
+// RemoveScheduleWindow removes the schedule window with the given name, returning whether or not it was found.
+//
+// Any records already held by the window are still summarized when the window would have ended.
+func (h slackHandler) RemoveScheduleWindow(name string) bool {
+	return h.schedule.remove(name)
+}
+
+// ScheduleWindows returns the handler's current schedule windows.
+func (h slackHandler) ScheduleWindows() []ScheduleWindow {
+	return h.schedule.list()
+}
+
+// SetScheduleWindow adds the given schedule window to the handler, replacing any existing window with the same name.
+//
+// Windows apply to this handler and every handler created from it or its parent using WithAttrs() or WithGroup(). If
+// more than one window is active at the same time, the one added first takes priority.
+func (h slackHandler) SetScheduleWindow(w ScheduleWindow) error {
+	return h.schedule.set(w)
+}
+
+// postHeldSummary posts a summary of the records held during a schedule window.
+//
+// The summary is delivered asynchronously as a pending record of the handler's lifecycle, so that shutting down the
+// handler waits for it, and cancels it, like any other record. If the handler has already been shut down, the summary
+// is dropped.
+func (h slackHandler) postHeldSummary(held *heldRecords) {
+	if err := h.lifecycle.begin(); err != nil {
+		h.lifecycle.dropped()
+		return
+	}
+	root := slackHandler{
+		goas:      []groupOrAttrs{},
+		lifecycle: h.lifecycle,
+		options:   h.options,
+		schedule:  h.schedule,
+	}
+	go func() {
+		h.lifecycle.end(root.handle(h.options.AddToContext(context.Background()), held.record(), nil,
+			h.options.WebhookURL))
+	}()
+}
+
+// releaseHeld stops schedule windows from holding records and posts the summaries of any records they currently
+// hold.
+func (h slackHandler) releaseHeld() {
+	h.schedule.release(h.postHeldSummary)
+}
@@ -0,0 +1,219 @@
+package slogxslack_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestScheduleRaiseLevel(t *testing.T) {
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Schedule: []slogxslack.ScheduleWindow{
+			{
+				Action:   slogxslack.ScheduleActionRaiseLevel,
+				Cron:     "* * * * *",
+				Duration: slogxslack.ConfigDuration(time.Minute),
+				Level:    "fatal",
+				Name:     "maintenance",
+				Timezone: "UTC",
+			},
+		},
+		WebhookURL: slogxslack.NewSecret("http://localhost"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	if handler.Enabled(context.Background(), slog.LevelError) {
+		t.Error("expected error records to be muted during the window")
+	}
+	if !handler.Enabled(context.Background(), slogx.LevelFatal.Level()) {
+		t.Error("expected fatal records to be enabled during the window")
+	}
+
+	if !handler.RemoveScheduleWindow("maintenance") {
+		t.Fatal("expected the window to be removed")
+	}
+	if !handler.Enabled(context.Background(), slog.LevelError) {
+		t.Error("expected error records to be enabled after the window was removed")
+	}
+}
+
+func TestScheduleRedirect(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+	redirect := slacktest.NewServer()
+	defer redirect.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	err = handler.SetScheduleWindow(slogxslack.ScheduleWindow{
+		Action:     slogxslack.ScheduleActionRedirect,
+		End:        time.Now().Add(time.Hour),
+		Name:       "migration",
+		WebhookURL: slogxslack.NewSecret(redirect.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to set window: %s", err.Error())
+	}
+
+	slog.New(handler).Error("redirected")
+	if len(redirect.Messages()) != 1 || len(server.Messages()) != 0 {
+		t.Errorf("expected the message to be redirected, got %d redirected and %d direct",
+			len(redirect.Messages()), len(server.Messages()))
+	}
+}
+
+func TestScheduleHold(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Schedule: []slogxslack.ScheduleWindow{
+			{
+				Action: slogxslack.ScheduleActionHold,
+				End:    time.Now().Add(200 * time.Millisecond),
+				Name:   "deploy",
+			},
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	logger := slog.New(handler)
+	logger.Warn("first")
+	logger.Error("second")
+	logger.Info("third")
+	if len(server.Messages()) != 0 {
+		t.Fatal("expected records to be held during the window")
+	}
+
+	messages := server.WaitForMessages(t, 1, 5*time.Second)
+	slacktest.AssertContainsText(t, messages[0], "3 record(s) were held during schedule window 'deploy'")
+	if err := handler.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %s", err.Error())
+	}
+	if stats := handler.Stats(); stats.Held != 3 || stats.Delivered != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestScheduleHoldReleasedOnShutdown(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Schedule: []slogxslack.ScheduleWindow{
+			{
+				Action: slogxslack.ScheduleActionHold,
+				End:    time.Now().Add(time.Hour),
+				Name:   "deploy",
+			},
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	slog.New(handler).Error("held")
+	if err := handler.ShutdownContext(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %s", err.Error())
+	}
+	if len(server.Messages()) != 1 {
+		t.Errorf("expected the summary to be posted on shutdown, got %d messages", len(server.Messages()))
+	}
+}
+
+func TestScheduleHoldSummaryHonorsShutdownDeadline(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		Schedule: []slogxslack.ScheduleWindow{
+			{
+				Action: slogxslack.ScheduleActionHold,
+				End:    time.Now().Add(time.Hour),
+				Name:   "deploy",
+			},
+		},
+		WebhookURL: slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	slog.New(handler).Error("held")
+
+	// the summary post hangs, so shutting down must give up on it once the context is done
+	server.Delay(1, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = handler.ShutdownContext(ctx)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected shutdown to stop waiting at the deadline, took %s", elapsed)
+	}
+	var abandoned *slogxslack.AbandonedRecordsError
+	if !errors.As(err, &abandoned) || abandoned.Count != 1 {
+		t.Errorf("expected the summary to be abandoned, got %v", err)
+	}
+}
+
+func TestScheduleCronOccurrence(t *testing.T) {
+	// a daily window which started 90 minutes ago is still active if it lasts 2 hours, but not if it lasts 1 hour
+	started := time.Now().UTC().Add(-90 * time.Minute)
+	cron := fmt.Sprintf("%d %d * * *", started.Minute(), started.Hour())
+	for duration, active := range map[time.Duration]bool{2 * time.Hour: true, time.Hour: false} {
+		handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+			Schedule: []slogxslack.ScheduleWindow{
+				{
+					Action:   slogxslack.ScheduleActionRaiseLevel,
+					Cron:     cron,
+					Duration: slogxslack.ConfigDuration(duration),
+					Name:     "backup",
+					Timezone: "UTC",
+				},
+			},
+			WebhookURL: slogxslack.NewSecret("http://localhost"),
+		})
+		if err != nil {
+			t.Fatalf("failed to create handler: %s", err.Error())
+		}
+		if enabled := handler.Enabled(context.Background(), slog.LevelError); enabled == active {
+			t.Errorf("%s: expected the window to be active: %t", duration, active)
+		}
+	}
+}
+
+func TestScheduleWindowValidation(t *testing.T) {
+	tests := map[string]slogxslack.ScheduleWindow{
+		"no_name":     {Action: slogxslack.ScheduleActionHold, End: time.Now()},
+		"bad_action":  {Action: "ignore", End: time.Now(), Name: "w"},
+		"no_end":      {Action: slogxslack.ScheduleActionHold, Name: "w"},
+		"bad_cron":    {Action: slogxslack.ScheduleActionHold, Cron: "61 * * * *", Duration: 1, Name: "w"},
+		"no_duration": {Action: slogxslack.ScheduleActionHold, Cron: "0 2 * * 0", Name: "w"},
+		"no_webhook":  {Action: slogxslack.ScheduleActionRedirect, End: time.Now(), Name: "w"},
+		"bad_zone":    {Action: slogxslack.ScheduleActionHold, End: time.Now(), Name: "w", Timezone: "Mars/Base"},
+	}
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		WebhookURL: slogxslack.NewSecret("http://localhost"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	for name, w := range tests {
+		if err := handler.SetScheduleWindow(w); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package slogxslack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RedactedSecret is the text output in place of a secret's value.
+	RedactedSecret = "[REDACTED]"
+
+	// DefaultSecretReloadInterval is the default minimum amount of time between checks for a rotated secret file.
+	DefaultSecretReloadInterval = 5 * time.Second
+)
+
+// Secret holds a sensitive value, such as a webhook URL or token, which redacts itself whenever it is printed,
+// logged or marshaled.
+//
+// A secret loaded from a file is automatically reloaded when the file changes, which allows the secret to be rotated
+// without restarting the application (eg: when the file is a mounted Kubernetes secret). Copies of a secret share the
+// same underlying value.
+type Secret struct {
+	// unexported variables
+	state *secretState
+}
+
+// secretState holds the actual value of a secret.
+type secretState struct {
+	lastCheck      time.Time
+	modTime        time.Time
+	mu             sync.Mutex
+	path           string
+	reloadInterval time.Duration
+	size           int64
+	value          string
+}
+
+// NewSecret creates a new secret holding the given value.
+func NewSecret(value string) Secret {
+	return Secret{
+		state: &secretState{
+			value: value,
+		},
+	}
+}
+
+// SecretFromEnv creates a new secret holding the value of the given environment variable.
+//
+// An error is returned if the environment variable is not set or is empty.
+func SecretFromEnv(name string) (Secret, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return Secret{}, fmt.Errorf("environment variable '%s' is not set or is empty", name)
+	}
+	return NewSecret(value), nil
+}
+
+// SecretFromFile creates a new secret holding the contents of the given file, with any surrounding whitespace
+// removed.
+//
+// The file is checked for changes at most once per reloadInterval whenever the secret's value is retrieved. If
+// reloadInterval is zero, DefaultSecretReloadInterval is used. If it is negative, the file is never reloaded. If the
+// file cannot be read when it is reloaded, the previous value is kept.
+func SecretFromFile(path string, reloadInterval time.Duration) (Secret, error) {
+	if reloadInterval == 0 {
+		reloadInterval = DefaultSecretReloadInterval
+	}
+	s := Secret{
+		state: &secretState{
+			path:           path,
+			reloadInterval: reloadInterval,
+		},
+	}
+	if err := s.Reload(); err != nil {
+		return Secret{}, err
+	}
+	return s, nil
+}
+
+// GoString returns the redacted value of the secret.
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// IsZero determines whether or not the secret is empty.
+//
+// The secret's file is not checked for changes, as a secret loaded from a file can never be empty.
+func (s Secret) IsZero() bool {
+	if s.state == nil {
+		return true
+	}
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	return s.state.value == ""
+}
+
+// LogValue returns the redacted value of the secret.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// MarshalJSON returns the redacted value of the secret.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+// MarshalText returns the redacted value of the secret.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Reload forces the secret to be reloaded from its file.
+//
+// If the secret was not loaded from a file, nothing is done.
+func (s Secret) Reload() error {
+	if s.state == nil || s.state.path == "" {
+		return nil
+	}
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	return s.state.reload()
+}
+
+// String returns the redacted value of the secret.
+//
+// If the secret is empty, an empty string is returned.
+func (s Secret) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	return RedactedSecret
+}
+
+// UnmarshalText sets the secret to the given value.
+func (s *Secret) UnmarshalText(text []byte) error {
+	*s = NewSecret(string(text))
+	return nil
+}
+
+// Value returns the actual value of the secret.
+//
+// If the secret was loaded from a file which has changed since it was last checked, the new value is returned.
+func (s Secret) Value() string {
+	if s.state == nil {
+		return ""
+	}
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if s.state.path != "" && s.state.reloadInterval > 0 && time.Since(s.state.lastCheck) >= s.state.reloadInterval {
+		_ = s.state.reload()
+	}
+	return s.state.value
+}
+
+// reload reads the secret from its file if the file has changed.
+//
+// The caller must hold the lock.
+func (s *secretState) reload() error {
+	s.lastCheck = time.Now()
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read secret file: %w", err)
+	}
+	if s.value != "" && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read secret file: %w", err)
+	}
+	value := string(bytes.TrimSpace(data))
+	if value == "" {
+		return errors.New("secret file is empty")
+	}
+	s.modTime, s.size, s.value = info.ModTime(), info.Size(), value
+	return nil
+}
+
+// scrubbedError is an error whose message has had any secrets removed from it.
+type scrubbedError struct {
+	err error
+	msg string
+}
+
+// Error returns the string version of the error.
+func (e *scrubbedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the underlying error.
+//
+// If the original error included a *url.Error, which contains the URL being requested, the error wrapped by it is
+// returned instead.
+func (e *scrubbedError) Unwrap() error {
+	return e.err
+}
+
+// scrubError removes the given secrets, and the path of any secret which is a URL, from the error's message.
+func scrubError(err error, secrets ...Secret) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	for _, s := range secrets {
+		value := s.Value()
+		if value == "" {
+			continue
+		}
+		msg = strings.ReplaceAll(msg, value, RedactedSecret)
+		if u, err := url.Parse(value); err == nil && len(u.Path) > 1 {
+			msg = strings.ReplaceAll(msg, u.Path, "/"+RedactedSecret)
+			if u.RawQuery != "" {
+				msg = strings.ReplaceAll(msg, u.RawQuery, RedactedSecret)
+			}
+		}
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return &scrubbedError{
+		err: err,
+		msg: msg,
+	}
+}
@@ -0,0 +1,111 @@
+package slogxslack_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	value := "https://hooks.slack.com/services/T000/B000/XXXX"
+	s := slogxslack.NewSecret(value)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("test", slog.Any("secret", s))
+	output, err := json.Marshal(struct{ URL slogxslack.Secret }{URL: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, text := range map[string]string{
+		"%v":   fmt.Sprintf("%v", s),
+		"%+v":  fmt.Sprintf("%+v", struct{ URL slogxslack.Secret }{URL: s}),
+		"%#v":  fmt.Sprintf("%#v", s),
+		"json": string(output),
+		"slog": buf.String(),
+	} {
+		if strings.Contains(text, "T000") {
+			t.Errorf("%s: secret was not redacted: %s", name, text)
+		}
+	}
+	if s.Value() != value {
+		t.Errorf("expected value %q, got %q", value, s.Value())
+	}
+}
+
+func TestSecretFromFileReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhook")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := slogxslack.SecretFromFile(path, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to load secret: %s", err.Error())
+	}
+	if s.Value() != "first" {
+		t.Fatalf("expected 'first', got %q", s.Value())
+	}
+
+	if err := os.WriteFile(path, []byte("second-value\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if s.Value() != "second-value" {
+		t.Errorf("expected the rotated secret, got %q", s.Value())
+	}
+}
+
+func TestConfigLoadsSecretFileOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhook")
+	if err := os.WriteFile(path, []byte("https://hooks.slack.com/services/T000/B000/XXXX\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := slogxslack.DefaultConfig()
+	c.WebhookURLFile = path
+	if err := c.Validate(); err != nil {
+		t.Fatalf("failed to validate configuration: %s", err.Error())
+	}
+
+	// the secret loaded during validation is reused, so the file is not read again
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := c.HandlerOptions()
+	if err != nil {
+		t.Fatalf("expected the secret loaded by Validate() to be reused, got %s", err.Error())
+	}
+	if !strings.Contains(opts.WebhookURL.Value(), "T000") {
+		t.Errorf("expected the webhook URL from the file, got %q", opts.WebhookURL.Value())
+	}
+}
+
+func TestDeliveryErrorScrubsWebhookURL(t *testing.T) {
+	server := slacktest.NewServer()
+	webhookURL := server.WebhookURL()
+	server.Close()
+
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		WebhookURL: slogxslack.NewSecret(webhookURL),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	err = handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "test", 0))
+	if err == nil {
+		t.Fatal("expected a delivery error")
+	}
+	path := strings.TrimPrefix(webhookURL, server.URL())
+	if strings.Contains(err.Error(), path) {
+		t.Errorf("error contains the webhook URL: %s", err.Error())
+	}
+}
@@ -0,0 +1,33 @@
+module go.innotegrity.dev/slogx-slack/slackotel
+
+go 1.21
+
+toolchain go1.21.1
+
+require (
+	// placeholder: bump to the first slogx-slack release with DeliveryObserver and LinksFormatter when tagging
+	go.innotegrity.dev/slogx-slack v0.2.0
+	go.opentelemetry.io/otel v1.19.0
+	go.opentelemetry.io/otel/metric v1.19.0
+	go.opentelemetry.io/otel/trace v1.19.0
+)
+
+require (
+	github.com/fatih/color v1.15.0 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/go-cmp v0.5.9 // indirect
+	github.com/gorilla/websocket v1.4.2 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.17 // indirect
+	github.com/slack-go/slack v0.12.3 // indirect
+	go.innotegrity.dev/errorx v1.0.15 // indirect
+	go.innotegrity.dev/generic v0.1.1 // indirect
+	go.innotegrity.dev/runtimex v0.1.0 // indirect
+	go.innotegrity.dev/slogx v0.3.1 // indirect
+	golang.org/x/sys v0.12.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// for development only; remove before tagging a release
+replace go.innotegrity.dev/slogx-slack => ../
@@ -0,0 +1,109 @@
+package slackotel
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// InstrumentationName is the name used for the tracer and meter created by the observer.
+	InstrumentationName = "go.innotegrity.dev/slogx-slack/slackotel"
+
+	// DeliveriesMetric is the name of the counter which records the number of messages posted to Slack.
+	DeliveriesMetric = "slogx.slack.deliveries"
+
+	// DeliveryDurationMetric is the name of the histogram which records the time taken to post messages to Slack.
+	DeliveryDurationMetric = "slogx.slack.delivery.duration"
+
+	// DeliverySpanName is the name of the span created for each message posted to Slack.
+	DeliverySpanName = "slack.post"
+)
+
+// ObserverOptions holds the options for the delivery observer.
+type ObserverOptions struct {
+	// MeterProvider is the provider used to create the meter for recording delivery metrics.
+	//
+	// If nil, the global meter provider is used.
+	MeterProvider metric.MeterProvider
+
+	// TracerProvider is the provider used to create the tracer for recording delivery spans.
+	//
+	// If nil, the global tracer provider is used.
+	TracerProvider trace.TracerProvider
+}
+
+// deliveryObserver records OpenTelemetry metrics and spans for each message posted to Slack.
+type deliveryObserver struct {
+	// unexported variables
+	deliveries metric.Int64Counter
+	duration   metric.Float64Histogram
+	tracer     trace.Tracer
+}
+
+// NewDeliveryObserver creates a new observer which can be used for SlackHandlerOptions.DeliveryObserver.
+func NewDeliveryObserver(opts ObserverOptions) (slogxslack.DeliveryObserver, error) {
+	// set default options
+	if opts.MeterProvider == nil {
+		opts.MeterProvider = otel.GetMeterProvider()
+	}
+	if opts.TracerProvider == nil {
+		opts.TracerProvider = otel.GetTracerProvider()
+	}
+
+	// create the instruments
+	meter := opts.MeterProvider.Meter(InstrumentationName)
+	deliveries, err := meter.Int64Counter(DeliveriesMetric,
+		metric.WithDescription("Number of log records posted to Slack."),
+		metric.WithUnit("{message}"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram(DeliveryDurationMetric,
+		metric.WithDescription("Time taken to post log records to Slack."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	// create the observer
+	return &deliveryObserver{
+		deliveries: deliveries,
+		duration:   duration,
+		tracer:     opts.TracerProvider.Tracer(InstrumentationName),
+	}, nil
+}
+
+// StartDelivery starts a new span for the message being posted to Slack.
+func (o *deliveryObserver) StartDelivery(ctx context.Context, level slog.Level) context.Context {
+	ctx, _ = o.tracer.Start(ctx, DeliverySpanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("log.level", level.String())))
+	return ctx
+}
+
+// EndDelivery ends the span for the message and records the delivery metrics.
+func (o *deliveryObserver) EndDelivery(ctx context.Context, level slog.Level, elapsed time.Duration, err error) {
+	outcome := "success"
+	span := trace.SpanFromContext(ctx)
+	if err != nil {
+		outcome = "failure"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+
+	attrs := metric.WithAttributes(
+		attribute.String("log.level", level.String()),
+		attribute.String("outcome", outcome),
+	)
+	o.deliveries.Add(ctx, 1, attrs)
+	o.duration.Record(ctx, elapsed.Seconds(), attrs)
+}
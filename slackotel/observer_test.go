@@ -0,0 +1,165 @@
+package slackotel_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slackotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewDeliveryObserver(t *testing.T) {
+	// the global providers are used by default
+	if _, err := slackotel.NewDeliveryObserver(slackotel.ObserverOptions{}); err != nil {
+		t.Fatalf("failed to create observer with the global providers: %s", err.Error())
+	}
+
+	meter := &recordingMeter{}
+	tracer := &recordingTracer{Tracer: trace.NewNoopTracerProvider().Tracer("")}
+	observer, err := slackotel.NewDeliveryObserver(slackotel.ObserverOptions{
+		MeterProvider:  recordingMeterProvider{meter: meter},
+		TracerProvider: recordingTracerProvider{tracer: tracer},
+	})
+	if err != nil {
+		t.Fatalf("failed to create observer: %s", err.Error())
+	}
+	var _ slogxslack.DeliveryObserver = observer
+
+	// a successful delivery
+	ctx := observer.StartDelivery(context.Background(), slog.LevelInfo)
+	observer.EndDelivery(ctx, slog.LevelInfo, 2*time.Second, nil)
+
+	// a failed delivery
+	ctx = observer.StartDelivery(context.Background(), slog.LevelError)
+	observer.EndDelivery(ctx, slog.LevelError, time.Second, errors.New("invalid_token"))
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(tracer.spans))
+	}
+	for _, span := range tracer.spans {
+		if span.name != slackotel.DeliverySpanName || !span.ended {
+			t.Errorf("expected an ended %q span, got %q (ended: %t)", slackotel.DeliverySpanName, span.name,
+				span.ended)
+		}
+	}
+	if tracer.spans[0].status != codes.Unset || tracer.spans[0].err != nil {
+		t.Errorf("expected no error on the successful span, got %v (%v)", tracer.spans[0].status, tracer.spans[0].err)
+	}
+	if tracer.spans[1].status != codes.Error || tracer.spans[1].err == nil {
+		t.Errorf("expected the error to be recorded on the failed span, got %v", tracer.spans[1].status)
+	}
+
+	expected := []string{"INFO/success", "ERROR/failure"}
+	if len(meter.deliveries) != len(expected) || len(meter.durations) != len(expected) {
+		t.Fatalf("expected %d measurements, got %d deliveries and %d durations", len(expected),
+			len(meter.deliveries), len(meter.durations))
+	}
+	for i, e := range expected {
+		if meter.deliveries[i] != e {
+			t.Errorf("expected delivery %d to be recorded as %q, got %q", i, e, meter.deliveries[i])
+		}
+	}
+	if meter.durations[0] != 2 || meter.durations[1] != 1 {
+		t.Errorf("expected durations of 2s and 1s, got %v", meter.durations)
+	}
+}
+
+// recordingMeterProvider returns the recording meter.
+type recordingMeterProvider struct {
+	noop.MeterProvider
+	meter *recordingMeter
+}
+
+func (p recordingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return p.meter
+}
+
+// recordingMeter creates instruments which record the level and outcome of each delivery along with its duration.
+type recordingMeter struct {
+	noop.Meter
+	deliveries []string
+	durations  []float64
+}
+
+func (m *recordingMeter) Int64Counter(string, ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return recordingCounter{meter: m}, nil
+}
+
+func (m *recordingMeter) Float64Histogram(string, ...metric.Float64HistogramOption) (metric.Float64Histogram,
+	error) {
+	return recordingHistogram{meter: m}, nil
+}
+
+type recordingCounter struct {
+	noop.Int64Counter
+	meter *recordingMeter
+}
+
+func (c recordingCounter) Add(_ context.Context, incr int64, opts ...metric.AddOption) {
+	attrs := metric.NewAddConfig(opts).Attributes()
+	level, _ := attrs.Value(attribute.Key("log.level"))
+	outcome, _ := attrs.Value(attribute.Key("outcome"))
+	for i := int64(0); i < incr; i++ {
+		c.meter.deliveries = append(c.meter.deliveries, level.AsString()+"/"+outcome.AsString())
+	}
+}
+
+type recordingHistogram struct {
+	noop.Float64Histogram
+	meter *recordingMeter
+}
+
+func (h recordingHistogram) Record(_ context.Context, value float64, _ ...metric.RecordOption) {
+	h.meter.durations = append(h.meter.durations, value)
+}
+
+// recordingTracerProvider returns the recording tracer.
+type recordingTracerProvider struct {
+	tracer *recordingTracer
+}
+
+func (p recordingTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return p.tracer
+}
+
+// recordingTracer starts spans which record their name, status and whether or not they were ended.
+type recordingTracer struct {
+	trace.Tracer
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context,
+	trace.Span) {
+	_, noopSpan := t.Tracer.Start(ctx, name, opts...)
+	span := &recordingSpan{Span: noopSpan, name: name}
+	t.spans = append(t.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type recordingSpan struct {
+	trace.Span
+	ended  bool
+	err    error
+	name   string
+	status codes.Code
+}
+
+func (s *recordingSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+func (s *recordingSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.err = err
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
@@ -0,0 +1,64 @@
+package slackotel
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// SpanIDAttr is the name of the attribute which holds the span ID.
+	SpanIDAttr = "span_id"
+
+	// TraceIDAttr is the name of the attribute which holds the trace ID.
+	TraceIDAttr = "trace_id"
+
+	// TraceLinkText is the default text to display for the trace link.
+	TraceLinkText = "View trace"
+)
+
+// TraceAttrs returns the trace and span IDs from the span in the given context as attributes.
+//
+// If the context does not carry a valid span, no attributes are returned.
+//
+// This function can be added to SlackHandlerOptions.ContextExtractors.
+func TraceAttrs(ctx context.Context) []slog.Attr {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []slog.Attr{
+		slog.String(TraceIDAttr, sc.TraceID().String()),
+		slog.String(SpanIDAttr, sc.SpanID().String()),
+	}
+}
+
+// TraceLinksFormatter returns a links formatter which builds a link to a trace viewer from the span in the context.
+//
+// The URL template may contain the placeholders {trace_id} and {span_id}, which are replaced with the trace and span
+// IDs respectively (eg: https://tracing.example.com/trace/{trace_id}?span={span_id}).
+//
+// If text is empty, TraceLinkText is used. If the context does not carry a valid span, no links are returned.
+//
+// The returned function can be used for SlackMessageFormatterOptions.LinksFormatter.
+func TraceLinksFormatter(urlTemplate, text string) slogxslack.FormatLinksFn {
+	if text == "" {
+		text = TraceLinkText
+	}
+	return func(ctx context.Context, level slog.Leveler) ([]slogxslack.SlackMessageLink, error) {
+		sc := trace.SpanContextFromContext(ctx)
+		if !sc.IsValid() || urlTemplate == "" {
+			return nil, nil
+		}
+		r := strings.NewReplacer("{trace_id}", sc.TraceID().String(), "{span_id}", sc.SpanID().String())
+		return []slogxslack.SlackMessageLink{
+			{
+				Text: text,
+				URL:  r.Replace(urlTemplate),
+			},
+		}, nil
+	}
+}
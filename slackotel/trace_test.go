@@ -0,0 +1,99 @@
+package slackotel_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	slogxslack "go.innotegrity.dev/slogx-slack"
+	"go.innotegrity.dev/slogx-slack/slackotel"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceAttrs(t *testing.T) {
+	if attrs := slackotel.TraceAttrs(context.Background()); len(attrs) != 0 {
+		t.Errorf("expected no attributes without a span, got %v", attrs)
+	}
+
+	ctx := spanContext(t)
+	attrs := slackotel.TraceAttrs(ctx)
+	if len(attrs) != 2 {
+		t.Fatalf("expected 2 attributes, got %d", len(attrs))
+	}
+	if attrs[0].Key != slackotel.TraceIDAttr || attrs[0].Value.String() != "0102030405060708090a0b0c0d0e0f10" {
+		t.Errorf("unexpected trace ID attribute: %v", attrs[0])
+	}
+	if attrs[1].Key != slackotel.SpanIDAttr || attrs[1].Value.String() != "0102030405060708" {
+		t.Errorf("unexpected span ID attribute: %v", attrs[1])
+	}
+}
+
+func TestTraceLinksFormatter(t *testing.T) {
+	fn := slackotel.TraceLinksFormatter("https://tracing.example.com/trace/{trace_id}?span={span_id}", "")
+	links, err := fn(spanContext(t), slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("failed to format links: %s", err.Error())
+	}
+	if len(links) != 1 {
+		t.Fatalf("expected 1 link, got %d", len(links))
+	}
+	expected := "https://tracing.example.com/trace/0102030405060708090a0b0c0d0e0f10?span=0102030405060708"
+	if links[0].URL != expected {
+		t.Errorf("expected URL %q, got %q", expected, links[0].URL)
+	}
+	if links[0].Text != slackotel.TraceLinkText {
+		t.Errorf("expected text %q, got %q", slackotel.TraceLinkText, links[0].Text)
+	}
+
+	links, err = fn(context.Background(), slog.LevelInfo)
+	if err != nil || len(links) != 0 {
+		t.Errorf("expected no links without a span, got %v (%v)", links, err)
+	}
+}
+
+func TestHandlerWithTrace(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.LinksFormatter = slackotel.TraceLinksFormatter("https://tracing.example.com/trace/{trace_id}", "Trace")
+	handler, err := slogxslack.NewSlackHandler(slogxslack.SlackHandlerOptions{
+		ContextExtractors: []slogxslack.ContextExtractorFn{slackotel.TraceAttrs},
+		RecordFormatter:   slogxslack.NewSlackMessageFormatter(opts),
+		WebhookURL:        slogxslack.NewSecret(server.WebhookURL()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err.Error())
+	}
+	slog.New(handler).ErrorContext(spanContext(t), "request failed")
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	for _, expected := range []string{
+		"*" + slackotel.TraceIDAttr + "*",
+		"0102030405060708090a0b0c0d0e0f10",
+		"https://tracing.example.com/trace/0102030405060708090a0b0c0d0e0f10",
+	} {
+		slacktest.AssertContainsText(t, messages[0], expected)
+	}
+}
+
+func spanContext(t *testing.T) context.Context {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	if err != nil {
+		t.Fatalf("failed to parse trace ID: %s", err.Error())
+	}
+	spanID, err := trace.SpanIDFromHex("0102030405060708")
+	if err != nil {
+		t.Fatalf("failed to parse span ID: %s", err.Error())
+	}
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
@@ -0,0 +1,143 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+const (
+	// DiscordWebhookPath is the path of the fake Discord Slack-compatible webhook URL served by the server.
+	DiscordWebhookPath = "/api/webhooks/000000000000000000/XXXXXXXXXXXXXXXXXXXXXXXX/slack"
+
+	// MattermostWebhookPath is the path of the fake Mattermost webhook URL served by the server.
+	MattermostWebhookPath = "/hooks/xxxxxxxxxxxxxxxxxxxxxxxxxx"
+
+	// RocketChatWebhookPath is the path of the fake Rocket.Chat webhook URL served by the server.
+	RocketChatWebhookPath = "/hooks/0000000000000000/XXXXXXXXXXXXXXXXXXXXXXXX"
+
+	// discordMaxAttachments is the maximum number of attachments (embeds) Discord accepts.
+	discordMaxAttachments = 10
+
+	// discordMaxAttachmentText is the maximum length of an attachment's text (an embed's description) in Discord.
+	discordMaxAttachmentText = 4096
+
+	// discordMaxText is the maximum length of a message's text in Discord.
+	discordMaxText = 2000
+)
+
+var (
+	// codeRegexp matches inline code spans, whose content is not interpreted as markup.
+	codeRegexp = regexp.MustCompile("`[^`]*`")
+
+	// slackBoldRegexp matches Slack's single asterisk bold markup, which is italic in standard markdown.
+	slackBoldRegexp = regexp.MustCompile(`(^|\s)\*[^*\s][^*\n]*\*($|[\s.,:])`)
+
+	// slackLinkRegexp matches Slack's link markup.
+	slackLinkRegexp = regexp.MustCompile(`<(?:https?|mailto):[^>]*>`)
+)
+
+// CompatWebhookURL returns the URL of the fake webhook for the given Slack-compatible target.
+//
+// Messages posted to the webhook are validated using ValidateCompatPayload(). Valid messages are recorded with the
+// profile as their endpoint (eg: EndpointMattermost), while invalid messages are rejected with an HTTP 400 status
+// code. If the profile is CompatibilitySlack or unknown, WebhookURL() is returned.
+func (s *Server) CompatWebhookURL(profile slogxslack.CompatibilityProfile) string {
+	switch profile {
+	case slogxslack.CompatibilityDiscord:
+		return s.server.URL + DiscordWebhookPath
+	case slogxslack.CompatibilityMattermost:
+		return s.server.URL + MattermostWebhookPath
+	case slogxslack.CompatibilityRocketChat:
+		return s.server.URL + RocketChatWebhookPath
+	}
+	return s.WebhookURL()
+}
+
+// ValidateCompatPayload checks that the JSON payload posted to a webhook only uses features supported by the given
+// Slack-compatible target.
+//
+// Every target requires a message with either text or an attachment with content, and rejects Block Kit blocks and
+// Slack's link markup. Mattermost and Discord also reject Slack's single asterisk bold markup, which they display as
+// italic text, and Discord enforces its message and embed size limits.
+func ValidateCompatPayload(profile slogxslack.CompatibilityProfile, body []byte) error {
+	var payload struct {
+		Attachments []slack.Attachment `json:"attachments"`
+		Blocks      []json.RawMessage  `json:"blocks"`
+		Text        string             `json:"text"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("payload is not a valid JSON message: %w", err)
+	}
+	if len(payload.Blocks) > 0 {
+		return fmt.Errorf("%s does not support Block Kit blocks", profile)
+	}
+
+	texts := []string{payload.Text}
+	hasContent := payload.Text != ""
+	for _, a := range payload.Attachments {
+		texts = append(texts, a.Pretext, a.Title, a.Text)
+		hasContent = hasContent || a.Pretext != "" || a.Title != "" || a.Text != "" || len(a.Fields) > 0
+		for _, f := range a.Fields {
+			texts = append(texts, f.Title, f.Value)
+		}
+		if profile == slogxslack.CompatibilityDiscord && utf8.RuneCountInString(a.Text) > discordMaxAttachmentText {
+			return fmt.Errorf("attachment text exceeds %d characters", discordMaxAttachmentText)
+		}
+	}
+	if !hasContent {
+		return errors.New("message has no text or attachment content")
+	}
+	for _, t := range texts {
+		t = codeRegexp.ReplaceAllString(t, "")
+		if slackLinkRegexp.MatchString(t) {
+			return fmt.Errorf("%s does not support Slack link markup: %q", profile, t)
+		}
+		if profile != slogxslack.CompatibilityRocketChat && slackBoldRegexp.MatchString(t) {
+			return fmt.Errorf("%s does not support Slack bold markup: %q", profile, t)
+		}
+	}
+	if profile == slogxslack.CompatibilityDiscord {
+		if utf8.RuneCountInString(payload.Text) > discordMaxText {
+			return fmt.Errorf("text exceeds %d characters", discordMaxText)
+		}
+		if len(payload.Attachments) > discordMaxAttachments {
+			return fmt.Errorf("message has more than %d attachments", discordMaxAttachments)
+		}
+	}
+	return nil
+}
+
+// handleCompatWebhook handles requests to the webhook URL of a Slack-compatible target.
+func (s *Server) handleCompatWebhook(profile slogxslack.CompatibilityProfile) http.HandlerFunc {
+	endpoint := string(profile)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "invalid_method", http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.applyFault(w, r, endpoint) {
+			return
+		}
+		m, err := readMessage(r)
+		if err != nil {
+			s.reject()
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+			return
+		}
+		if err := ValidateCompatPayload(profile, m.Body); err != nil {
+			s.reject()
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m.Endpoint = endpoint
+		s.record(m)
+		_, _ = w.Write([]byte("ok"))
+	}
+}
@@ -0,0 +1,6 @@
+// Package slacktest provides an in-process fake Slack server and helpers for testing code which logs to Slack.
+//
+// The fake server accepts incoming webhooks as well as the chat.postMessage, chat.update and file upload Web API
+// methods. Every message it accepts is recorded so that tests can assert on the blocks it received, and faults such
+// as rate limiting, server errors and timeouts can be injected to exercise retry and error handling code.
+package slacktest
@@ -0,0 +1,262 @@
+package slacktest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+const (
+	// BlockKitBuilderBaseURL is the base URL of Slack's Block Kit Builder.
+	BlockKitBuilderBaseURL = "https://app.slack.com/block-kit-builder/#"
+
+	// FixturePC is the program counter used by the fixture records.
+	//
+	// It is not a real program counter; use FixtureOptions() to make sure the source location is formatted
+	// deterministically.
+	FixturePC uintptr = 0x1234
+
+	// FixtureSource is the source location output by the formatter returned by FixtureOptions().
+	FixtureSource = "slacktest/fixture.go:42"
+
+	// GoldenDir is the directory, relative to the test's working directory, which holds the golden files.
+	GoldenDir = "testdata/golden"
+
+	// UpdateFlag is the name of the command-line flag registered by RegisterUpdateFlag().
+	UpdateFlag = "update"
+)
+
+var (
+	// FixtureTime is the time used by the fixture records.
+	FixtureTime = time.Date(2023, time.October, 2, 15, 4, 5, 0, time.UTC)
+
+	// UpdateGolden determines whether AssertGolden() writes golden files with the output instead of comparing them.
+	//
+	// It is set by the flag registered using RegisterUpdateFlag().
+	UpdateGolden bool
+)
+
+// RegisterUpdateFlag registers the -update flag, which sets UpdateGolden, with the given flag set.
+//
+// The package does not register any flags itself. Call this function from an init() function or TestMain() in the
+// test package (eg: slacktest.RegisterUpdateFlag(flag.CommandLine)) before the flags are parsed. If the flag set
+// already has an -update flag, it is left unchanged.
+func RegisterUpdateFlag(fs *flag.FlagSet) {
+	if fs.Lookup(UpdateFlag) == nil {
+		fs.BoolVar(&UpdateGolden, UpdateFlag, false, "update golden files instead of comparing them")
+	}
+}
+
+// FixtureRecord holds the data for a record used to produce deterministic formatter output.
+type FixtureRecord struct {
+	// Attrs holds the record's attributes.
+	Attrs []slog.Attr
+
+	// Level is the record's level.
+	Level slogx.Level
+
+	// Message is the record's message.
+	Message string
+
+	// Name is a unique name for the record which is used as its key in the output.
+	Name string
+
+	// PC is the record's program counter.
+	PC uintptr
+
+	// Time is the record's time.
+	Time time.Time
+}
+
+// fixtureValuer is a type implementing slog.LogValuer used by the fixture records.
+type fixtureValuer struct{}
+
+// LogValue returns the value to log for the object.
+func (fixtureValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("id", "1234"), slog.String("name", "valuer"))
+}
+
+// fixtureStruct is a type without any special formatting used by the fixture records.
+type fixtureStruct struct {
+	Name  string
+	Count int
+}
+
+// FixtureRecords returns a deterministic set of records covering every level defined by slogx and every slog.Kind.
+func FixtureRecords() []FixtureRecord {
+	levels := []slogx.Level{slogx.LevelTrace, slogx.LevelDebug, slogx.LevelInfo, slogx.LevelNotice, slogx.LevelWarn,
+		slogx.LevelError, slogx.LevelFatal, slogx.LevelPanic}
+	records := []FixtureRecord{}
+	for _, l := range levels {
+		records = append(records, FixtureRecord{
+			Level:   l,
+			Message: fmt.Sprintf("this is a %s message", l.String()),
+			Name:    fmt.Sprintf("level_%s", l.String()),
+			PC:      FixturePC,
+			Time:    FixtureTime,
+		})
+	}
+	records = append(records,
+		FixtureRecord{
+			Attrs: []slog.Attr{
+				slog.Any("any_error", errors.New("something went wrong")),
+				slog.Any("any_map", map[string]int{"a": 1, "b": 2}),
+				slog.Any("any_slice", []string{"one", "two"}),
+				slog.Any("any_struct", fixtureStruct{Name: "fixture", Count: 3}),
+				slog.Bool("bool", true),
+				slog.Duration("duration", 1500*time.Millisecond),
+				slog.Float64("float64", 3.14),
+				slog.Group("group",
+					slog.String("string", "grouped"),
+					slog.Group("nested", slog.Int("int64", -1)),
+				),
+				slog.Int64("int64", -42),
+				slog.Any("log_valuer", fixtureValuer{}),
+				slog.String("string", "value with *markdown* and <brackets>"),
+				slog.Time("time", FixtureTime.Add(time.Hour)),
+				slog.Uint64("uint64", 42),
+			},
+			Level:   slogx.LevelError,
+			Message: "this is a message with every kind of attribute",
+			Name:    "attr_kinds",
+			PC:      FixturePC,
+			Time:    FixtureTime,
+		},
+		FixtureRecord{
+			Level:   slogx.LevelInfo,
+			Message: "this is a message without a time or source location",
+			Name:    "zero_time_pc",
+		},
+		FixtureRecord{
+			Level:   slogx.LevelWarn,
+			Message: "this is a message\nspanning multiple lines\n\twith indentation",
+			Name:    "multiline",
+			PC:      FixturePC,
+			Time:    FixtureTime,
+		},
+	)
+	return records
+}
+
+// FixtureOptions returns a copy of the options with the source and time formatters replaced so that the output for
+// the fixture records does not depend on the machine running the tests.
+//
+// The source location is always formatted as FixtureSource and times are formatted in UTC using the RFC3339 layout.
+func FixtureOptions(opts slogxslack.SlackMessageFormatterOptions) slogxslack.SlackMessageFormatterOptions {
+	opts.SourceFormatter = func(ctx context.Context, level slog.Leveler, pc uintptr) (string, error) {
+		return FixtureSource, nil
+	}
+	opts.TimeFormatter = func(ctx context.Context, level slog.Leveler, t time.Time) (string, error) {
+		return t.UTC().Format(time.RFC3339), nil
+	}
+	return opts
+}
+
+// FormatFixtures formats each of the records using the given formatter and returns the resulting messages as
+// indented JSON, keyed by the name of each record.
+func FormatFixtures(ctx context.Context, f slogxslack.SlackMessageFormatter, records []FixtureRecord) ([]byte,
+	error) {
+
+	messages := map[string]*slack.WebhookMessage{}
+	for _, r := range records {
+		message, err := f.FormatRecord(ctx, r.Time, r.Level, r.PC, r.Message, r.Attrs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format record %q: %w", r.Name, err)
+		}
+		messages[r.Name] = message
+	}
+	output, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(output, '\n'), nil
+}
+
+// AssertGolden compares the output against the golden file with the given name.
+//
+// The golden file is stored in GoldenDir as NAME.json. If UpdateGolden is true (see RegisterUpdateFlag()), the golden
+// file is written with the output instead of being compared.
+func AssertGolden(t testing.TB, name string, output []byte) {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".json")
+	if UpdateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %s", err.Error())
+		}
+		if err := os.WriteFile(path, output, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %s", err.Error())
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -%s to create it): %s", UpdateFlag, err.Error())
+	}
+	if !bytes.Equal(expected, output) {
+		t.Errorf("output does not match golden file %s (run with -%s to update it)\n--- expected\n%s\n--- actual\n%s",
+			path, UpdateFlag, expected, output)
+	}
+}
+
+// AssertFormatterGolden formats the fixture records using the given formatter and compares the output against the
+// golden file with the given name.
+//
+// If the output does not match, a Block Kit Builder preview URL is logged for each message which differs so that the
+// layout can be reviewed.
+func AssertFormatterGolden(t testing.TB, name string, f slogxslack.SlackMessageFormatter) {
+	t.Helper()
+	records := FixtureRecords()
+	output, err := FormatFixtures(context.Background(), f, records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertGolden(t, name, output)
+	if !t.Failed() {
+		return
+	}
+	for _, r := range records {
+		message, err := f.FormatRecord(context.Background(), r.Time, r.Level, r.PC, r.Message, r.Attrs)
+		if err != nil {
+			continue
+		}
+		if previewURL, err := BlockKitBuilderURL(message); err == nil {
+			t.Logf("preview for %q: %s", r.Name, previewURL)
+		}
+	}
+}
+
+// BlockKitBuilderURL returns a URL which opens the given message in Slack's Block Kit Builder.
+func BlockKitBuilderURL(message *slack.WebhookMessage) (string, error) {
+	if message == nil {
+		return "", errors.New("message cannot be nil")
+	}
+	payload := map[string]any{}
+	if message.Blocks != nil {
+		payload["blocks"] = message.Blocks.BlockSet
+	}
+	if len(message.Attachments) > 0 {
+		payload["attachments"] = message.Attachments
+	}
+	if message.Text != "" {
+		payload["text"] = message.Text
+	}
+	output, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return BlockKitBuilderBaseURL + url.PathEscape(string(output)), nil
+}
@@ -0,0 +1,25 @@
+package slacktest_test
+
+import (
+	"flag"
+	"testing"
+
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestRegisterUpdateFlag(t *testing.T) {
+	if flag.Lookup(slacktest.UpdateFlag) != nil {
+		t.Fatalf("expected the package not to register -%s itself", slacktest.UpdateFlag)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	slacktest.RegisterUpdateFlag(fs)
+	slacktest.RegisterUpdateFlag(fs)
+	defer func() { slacktest.UpdateGolden = false }()
+	if err := fs.Parse([]string{"-" + slacktest.UpdateFlag}); err != nil {
+		t.Fatalf("failed to parse flags: %s", err.Error())
+	}
+	if !slacktest.UpdateGolden {
+		t.Errorf("expected -%s to set UpdateGolden", slacktest.UpdateFlag)
+	}
+}
@@ -0,0 +1,236 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// EndpointWebhook is the endpoint name recorded for messages posted to the webhook URL.
+	EndpointWebhook = "webhook"
+
+	// EndpointPostMessage is the endpoint name recorded for messages posted using chat.postMessage.
+	EndpointPostMessage = "chat.postMessage"
+
+	// EndpointUpdateMessage is the endpoint name recorded for messages updated using chat.update.
+	EndpointUpdateMessage = "chat.update"
+
+	// EndpointFileUpload is the endpoint name recorded for files uploaded using files.upload or
+	// files.completeUploadExternal.
+	EndpointFileUpload = "files.upload"
+
+	// EndpointDiscord is the endpoint name recorded for messages posted to the fake Discord webhook URL.
+	EndpointDiscord = "discord"
+
+	// EndpointMattermost is the endpoint name recorded for messages posted to the fake Mattermost webhook URL.
+	EndpointMattermost = "mattermost"
+
+	// EndpointRocketChat is the endpoint name recorded for messages posted to the fake Rocket.Chat webhook URL.
+	EndpointRocketChat = "rocketchat"
+)
+
+// Message is a message received by the fake Slack server.
+type Message struct {
+	// Attachments holds any legacy attachments included with the message.
+	Attachments []slack.Attachment
+
+	// Blocks holds any Block Kit blocks included with the message.
+	Blocks slack.Blocks
+
+	// Body is the raw body of the request.
+	Body []byte
+
+	// Channel is the channel the message was posted to, if one was supplied.
+	Channel string
+
+	// Endpoint is the name of the endpoint which received the message (eg: EndpointWebhook).
+	Endpoint string
+
+	// FileContent holds the content of an uploaded file.
+	FileContent []byte
+
+	// FileName is the name of an uploaded file.
+	FileName string
+
+	// Header holds the headers of the request.
+	Header http.Header
+
+	// ReceivedAt is the time the message was received by the server.
+	ReceivedAt time.Time
+
+	// Text is the top-level text of the message.
+	Text string
+
+	// ThreadTimestamp is the timestamp of the parent message if the message was posted to a thread.
+	ThreadTimestamp string
+
+	// Timestamp is the timestamp assigned to the message by the server or, for updates, the timestamp of the message
+	// being updated.
+	Timestamp string
+}
+
+// BlockTypes returns the type of every block in the message, in order.
+func (m Message) BlockTypes() []slack.MessageBlockType {
+	types := []slack.MessageBlockType{}
+	for _, b := range m.Blocks.BlockSet {
+		types = append(types, b.BlockType())
+	}
+	return types
+}
+
+// ContainsText determines whether or not any text in the message contains the given string.
+func (m Message) ContainsText(s string) bool {
+	for _, t := range m.Texts() {
+		if strings.Contains(t, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Texts returns every piece of text found in the message, including the top-level text, the text of any blocks and
+// the text of any attachments and their blocks.
+func (m Message) Texts() []string {
+	texts := []string{}
+	add := func(t string) {
+		if t != "" {
+			texts = append(texts, t)
+		}
+	}
+
+	add(m.Text)
+	texts = append(texts, blockTexts(m.Blocks.BlockSet)...)
+	for _, a := range m.Attachments {
+		add(a.Pretext)
+		add(a.Title)
+		add(a.Text)
+		add(a.Fallback)
+		for _, f := range a.Fields {
+			add(f.Title)
+			add(f.Value)
+		}
+		texts = append(texts, blockTexts(a.Blocks.BlockSet)...)
+	}
+	return texts
+}
+
+// blockTexts returns the text of any blocks.
+func blockTexts(blocks []slack.Block) []string {
+	texts := []string{}
+	add := func(t string) {
+		if t != "" {
+			texts = append(texts, t)
+		}
+	}
+	addObject := func(o *slack.TextBlockObject) {
+		if o != nil {
+			add(o.Text)
+		}
+	}
+
+	for _, b := range blocks {
+		switch block := b.(type) {
+		case *slack.HeaderBlock:
+			addObject(block.Text)
+		case *slack.SectionBlock:
+			addObject(block.Text)
+			for _, f := range block.Fields {
+				addObject(f)
+			}
+		case *slack.ContextBlock:
+			for _, e := range block.ContextElements.Elements {
+				switch element := e.(type) {
+				case *slack.TextBlockObject:
+					add(element.Text)
+				case *slack.ImageBlockElement:
+					add(element.AltText)
+				}
+			}
+		case *slack.RichTextBlock:
+			texts = append(texts, richTextTexts(block.Elements)...)
+		}
+	}
+	return texts
+}
+
+// richTextTexts returns the text of any rich text elements.
+func richTextTexts(elements []slack.RichTextElement) []string {
+	texts := []string{}
+	for _, e := range elements {
+		switch element := e.(type) {
+		case *slack.RichTextSection:
+			for _, se := range element.Elements {
+				switch sectionElement := se.(type) {
+				case *slack.RichTextSectionTextElement:
+					texts = append(texts, sectionElement.Text)
+				case *slack.RichTextSectionLinkElement:
+					texts = append(texts, sectionElement.URL)
+				}
+			}
+		case *slack.RichTextUnknown:
+			var raw any
+			if err := json.Unmarshal([]byte(element.Raw), &raw); err == nil {
+				texts = append(texts, rawTexts(raw)...)
+			}
+		}
+	}
+	return texts
+}
+
+// rawTexts returns the value of every "text" and "url" field found in the decoded JSON value.
+func rawTexts(v any) []string {
+	texts := []string{}
+	switch value := v.(type) {
+	case map[string]any:
+		for _, key := range []string{"text", "url"} {
+			if s, ok := value[key].(string); ok && s != "" {
+				texts = append(texts, s)
+			}
+		}
+		if elements, ok := value["elements"]; ok {
+			texts = append(texts, rawTexts(elements)...)
+		}
+	case []any:
+		for _, e := range value {
+			texts = append(texts, rawTexts(e)...)
+		}
+	}
+	return texts
+}
+
+// AssertBlockTypes fails the test if the types of the blocks in the message do not exactly match the given types.
+func AssertBlockTypes(t testing.TB, m Message, types ...slack.MessageBlockType) {
+	t.Helper()
+	actual := m.BlockTypes()
+	if len(actual) != len(types) {
+		t.Errorf("expected block types %v, got %v", types, actual)
+		return
+	}
+	for i := range types {
+		if actual[i] != types[i] {
+			t.Errorf("expected block types %v, got %v", types, actual)
+			return
+		}
+	}
+}
+
+// AssertContainsText fails the test if no text in the message contains the given string.
+func AssertContainsText(t testing.TB, m Message, s string) {
+	t.Helper()
+	if !m.ContainsText(s) {
+		t.Errorf("expected message to contain %q, got %q", s, m.Texts())
+	}
+}
+
+// AssertNotContainsText fails the test if any text in the message contains the given string.
+func AssertNotContainsText(t testing.TB, m Message, s string) {
+	t.Helper()
+	if m.ContainsText(s) {
+		t.Errorf("expected message not to contain %q, got %q", s, m.Texts())
+	}
+}
@@ -0,0 +1,488 @@
+package slacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+const (
+	// DefaultChannelID is the channel ID returned by the server when a message does not specify a channel.
+	DefaultChannelID = "C0000000000"
+
+	// WebhookPath is the path of the webhook URL served by the server.
+	WebhookPath = "/services/T0000000000/B0000000000/XXXXXXXXXXXXXXXXXXXXXXXX"
+)
+
+// Fault describes an error to return instead of accepting a request.
+type Fault struct {
+	// Delay is the amount of time to wait before responding.
+	//
+	// If the client gives up on the request before the delay expires, the request is abandoned. This can be used to
+	// simulate timeouts.
+	Delay time.Duration
+
+	// Endpoint restricts the fault to requests for the given endpoint (eg: EndpointWebhook).
+	//
+	// If this is empty, the fault applies to the next request for any endpoint.
+	Endpoint string
+
+	// RetryAfter is the value of the Retry-After header to return along with an HTTP 429 status code.
+	RetryAfter time.Duration
+
+	// StatusCode is the HTTP status code to return.
+	//
+	// If this is zero and Delay is set, the request is accepted normally after the delay.
+	StatusCode int
+}
+
+// Server is a fake Slack server which records the messages it receives.
+//
+// The server is safe for concurrent use.
+type Server struct {
+	// unexported variables
+	faults   []Fault
+	messages []Message
+	mu       sync.Mutex
+	rejected int
+	server   *httptest.Server
+	ts       int64
+	updated  chan struct{}
+}
+
+// NewServer creates and starts a new fake Slack server.
+//
+// The server should be closed by calling Close() when it is no longer needed.
+func NewServer() *Server {
+	s := &Server{
+		faults:   []Fault{},
+		messages: []Message{},
+		updated:  make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(WebhookPath, s.handleWebhook)
+	mux.HandleFunc("/api/auth.test", s.handleAuthTest)
+	mux.HandleFunc("/api/chat.postMessage", s.handleChat(EndpointPostMessage))
+	mux.HandleFunc("/api/chat.update", s.handleChat(EndpointUpdateMessage))
+	mux.HandleFunc("/api/files.upload", s.handleFileUpload)
+	mux.HandleFunc("/api/files.getUploadURLExternal", s.handleGetUploadURL)
+	mux.HandleFunc("/api/files.completeUploadExternal", s.handleCompleteUpload)
+	mux.HandleFunc("/upload/", s.handleUploadURL)
+	mux.HandleFunc(DiscordWebhookPath, s.handleCompatWebhook(slogxslack.CompatibilityDiscord))
+	mux.HandleFunc(MattermostWebhookPath, s.handleCompatWebhook(slogxslack.CompatibilityMattermost))
+	mux.HandleFunc(RocketChatWebhookPath, s.handleCompatWebhook(slogxslack.CompatibilityRocketChat))
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// APIURL returns the base URL of the Web API served by the server.
+//
+// The URL can be passed to slack.OptionAPIURL() when creating a Slack client.
+func (s *Server) APIURL() string {
+	return s.server.URL + "/api/"
+}
+
+// Client returns an HTTP client which is configured to talk to the server.
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+// Close shuts down the server, abandoning any delayed requests.
+func (s *Server) Close() {
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+// URL returns the base URL of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// WebhookURL returns the webhook URL served by the server.
+func (s *Server) WebhookURL() string {
+	return s.server.URL + WebhookPath
+}
+
+// Delay causes the next count requests to wait for the given duration before being accepted.
+func (s *Server) Delay(count int, d time.Duration) {
+	s.InjectFault(count, Fault{Delay: d})
+}
+
+// Fail causes the next count requests to be rejected with the given HTTP status code.
+func (s *Server) Fail(count int, statusCode int) {
+	s.InjectFault(count, Fault{StatusCode: statusCode})
+}
+
+// InjectFault causes the next count requests matching the fault to fail.
+//
+// Faults are applied in the order they were injected.
+func (s *Server) InjectFault(count int, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < count; i++ {
+		s.faults = append(s.faults, f)
+	}
+}
+
+// RateLimit causes the next count requests to be rejected with an HTTP 429 status code and the given Retry-After
+// duration.
+func (s *Server) RateLimit(count int, retryAfter time.Duration) {
+	s.InjectFault(count, Fault{
+		RetryAfter: retryAfter,
+		StatusCode: http.StatusTooManyRequests,
+	})
+}
+
+// Messages returns a copy of every message accepted by the server, in the order they were received.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message{}, s.messages...)
+}
+
+// Rejected returns the number of requests which were rejected because of an injected fault or, for the webhooks of
+// Slack-compatible targets, an invalid payload.
+func (s *Server) Rejected() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rejected
+}
+
+// Reset clears any recorded messages and pending faults.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = []Fault{}
+	s.messages = []Message{}
+	s.rejected = 0
+}
+
+// WaitForMessages waits until the server has accepted at least n messages and returns them.
+//
+// If the messages are not received before the timeout expires, the test fails immediately.
+func (s *Server) WaitForMessages(t testing.TB, n int, timeout time.Duration) []Message {
+	t.Helper()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		if len(s.messages) >= n {
+			messages := append([]Message{}, s.messages...)
+			s.mu.Unlock()
+			return messages
+		}
+		updated := s.updated
+		received := len(s.messages)
+		s.mu.Unlock()
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			t.Fatalf("timed out waiting for %d message(s); received %d", n, received)
+			return nil
+		}
+	}
+}
+
+// applyFault applies the next matching fault (if any) to the request.
+//
+// If the request should not be accepted, false is returned.
+func (s *Server) applyFault(w http.ResponseWriter, r *http.Request, endpoint string) bool {
+	s.mu.Lock()
+	var fault *Fault
+	for i, f := range s.faults {
+		if f.Endpoint == "" || f.Endpoint == endpoint {
+			fault = &f
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+	if fault == nil {
+		return true
+	}
+
+	if fault.Delay > 0 {
+		// the body must be fully read for the request context to be cancelled when the client disconnects
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.reject()
+			return false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		select {
+		case <-time.After(fault.Delay):
+		case <-r.Context().Done():
+			s.reject()
+			return false
+		}
+	}
+	if fault.StatusCode == 0 {
+		return true
+	}
+	s.reject()
+	if fault.StatusCode == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+	}
+	http.Error(w, http.StatusText(fault.StatusCode), fault.StatusCode)
+	return false
+}
+
+// handleAuthTest handles requests to the auth.test Web API method.
+func (s *Server) handleAuthTest(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"ok":      true,
+		"team_id": "T0000000000",
+		"user_id": "U0000000000",
+	})
+}
+
+// handleChat handles requests to the chat.postMessage and chat.update Web API methods.
+func (s *Server) handleChat(endpoint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.applyFault(w, r, endpoint) {
+			return
+		}
+		m, err := readMessage(r)
+		if err != nil {
+			writeJSON(w, map[string]any{"ok": false, "error": "invalid_arguments"})
+			return
+		}
+		m.Endpoint = endpoint
+		if m.Channel == "" {
+			m.Channel = DefaultChannelID
+		}
+		if endpoint == EndpointPostMessage {
+			m.Timestamp = s.nextTimestamp()
+		} else if m.Timestamp == "" {
+			writeJSON(w, map[string]any{"ok": false, "error": "message_not_found"})
+			return
+		}
+		s.record(m)
+		writeJSON(w, map[string]any{
+			"ok":      true,
+			"channel": m.Channel,
+			"ts":      m.Timestamp,
+			"text":    m.Text,
+		})
+	}
+}
+
+// handleCompleteUpload handles requests to the files.completeUploadExternal Web API method.
+func (s *Server) handleCompleteUpload(w http.ResponseWriter, r *http.Request) {
+	if !s.applyFault(w, r, EndpointFileUpload) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, map[string]any{"ok": false, "error": "invalid_arguments"})
+		return
+	}
+	var files []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+	_ = json.Unmarshal([]byte(r.Form.Get("files")), &files)
+	summaries := []map[string]any{}
+	for _, f := range files {
+		s.record(Message{
+			Channel:         r.Form.Get("channel_id"),
+			Endpoint:        EndpointFileUpload,
+			FileName:        f.Title,
+			Header:          r.Header.Clone(),
+			Text:            r.Form.Get("initial_comment"),
+			ThreadTimestamp: r.Form.Get("thread_ts"),
+		})
+		summaries = append(summaries, map[string]any{"id": f.ID, "title": f.Title})
+	}
+	writeJSON(w, map[string]any{"ok": true, "files": summaries})
+}
+
+// handleFileUpload handles requests to the files.upload Web API method.
+func (s *Server) handleFileUpload(w http.ResponseWriter, r *http.Request) {
+	if !s.applyFault(w, r, EndpointFileUpload) {
+		return
+	}
+	m := Message{
+		Endpoint: EndpointFileUpload,
+		Header:   r.Header.Clone(),
+	}
+	if err := r.ParseMultipartForm(32 << 20); err == nil {
+		if f, header, err := r.FormFile("file"); err == nil {
+			m.FileContent, _ = io.ReadAll(f)
+			m.FileName = header.Filename
+			f.Close()
+		}
+	} else if err := r.ParseForm(); err != nil {
+		writeJSON(w, map[string]any{"ok": false, "error": "invalid_arguments"})
+		return
+	}
+	if content := r.Form.Get("content"); content != "" {
+		m.FileContent = []byte(content)
+	}
+	if filename := r.Form.Get("filename"); filename != "" {
+		m.FileName = filename
+	}
+	m.Channel = r.Form.Get("channels")
+	m.Text = r.Form.Get("initial_comment")
+	m.ThreadTimestamp = r.Form.Get("thread_ts")
+	m.Timestamp = s.nextTimestamp()
+	s.record(m)
+	writeJSON(w, map[string]any{
+		"ok": true,
+		"file": map[string]any{
+			"id":    "F" + strings.ReplaceAll(m.Timestamp, ".", ""),
+			"name":  m.FileName,
+			"title": r.Form.Get("title"),
+		},
+	})
+}
+
+// handleGetUploadURL handles requests to the files.getUploadURLExternal Web API method.
+func (s *Server) handleGetUploadURL(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, map[string]any{"ok": false, "error": "invalid_arguments"})
+		return
+	}
+	fileID := "F" + strings.ReplaceAll(s.nextTimestamp(), ".", "")
+	writeJSON(w, map[string]any{
+		"ok":      true,
+		"file_id": fileID,
+		"upload_url": fmt.Sprintf("%s/upload/%s?filename=%s", s.server.URL, fileID,
+			url.QueryEscape(r.Form.Get("filename"))),
+	})
+}
+
+// handleUploadURL handles uploads to URLs returned by files.getUploadURLExternal.
+func (s *Server) handleUploadURL(w http.ResponseWriter, r *http.Request) {
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.record(Message{
+		Body:        content,
+		Endpoint:    "upload",
+		FileContent: content,
+		FileName:    r.URL.Query().Get("filename"),
+		Header:      r.Header.Clone(),
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleWebhook handles requests to the webhook URL.
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid_method", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.applyFault(w, r, EndpointWebhook) {
+		return
+	}
+	m, err := readMessage(r)
+	if err != nil {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+	m.Endpoint = EndpointWebhook
+	s.record(m)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// nextTimestamp returns a new unique message timestamp.
+func (s *Server) nextTimestamp() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ts++
+	return fmt.Sprintf("1700000000.%06d", s.ts)
+}
+
+// record saves the message and notifies anyone waiting for messages.
+func (s *Server) record(m Message) {
+	m.ReceivedAt = time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, m)
+	close(s.updated)
+	s.updated = make(chan struct{})
+}
+
+// reject increments the number of rejected requests.
+func (s *Server) reject() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejected++
+}
+
+// readMessage reads a message from either a JSON or form-encoded request body.
+func readMessage(r *http.Request) (Message, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Message{}, err
+	}
+	m := Message{
+		Body:   body,
+		Header: r.Header.Clone(),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var payload struct {
+			Attachments     []slack.Attachment `json:"attachments"`
+			Blocks          slack.Blocks       `json:"blocks"`
+			Channel         string             `json:"channel"`
+			Text            string             `json:"text"`
+			ThreadTimestamp string             `json:"thread_ts"`
+			Timestamp       string             `json:"ts"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return Message{}, err
+		}
+		m.Attachments = payload.Attachments
+		m.Blocks = payload.Blocks
+		m.Channel = payload.Channel
+		m.Text = payload.Text
+		m.ThreadTimestamp = payload.ThreadTimestamp
+		m.Timestamp = payload.Timestamp
+		return m, nil
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return Message{}, err
+	}
+	if blocks := values.Get("blocks"); blocks != "" {
+		if err := json.Unmarshal([]byte(blocks), &m.Blocks); err != nil {
+			return Message{}, err
+		}
+	}
+	if attachments := values.Get("attachments"); attachments != "" {
+		if err := json.Unmarshal([]byte(attachments), &m.Attachments); err != nil {
+			return Message{}, err
+		}
+	}
+	m.Channel = values.Get("channel")
+	m.Text = values.Get("text")
+	m.ThreadTimestamp = values.Get("thread_ts")
+	m.Timestamp = values.Get("ts")
+	return m, nil
+}
+
+// writeJSON writes the given value to the response as JSON.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,104 @@
+package slacktest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx-slack/slacktest"
+)
+
+func TestWebhookFaults(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	msg := &slack.WebhookMessage{
+		Blocks: &slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "hello *world*", false, false), nil,
+					nil),
+			},
+		},
+	}
+
+	server.RateLimit(1, 2*time.Second)
+	err := slack.PostWebhookCustomHTTP(server.WebhookURL(), server.Client(), msg)
+	var rateLimitedErr *slack.RateLimitedError
+	if !errors.As(err, &rateLimitedErr) || rateLimitedErr.RetryAfter != 2*time.Second {
+		t.Errorf("expected a rate limit error with a 2s retry, got: %v", err)
+	}
+
+	server.Fail(1, http.StatusInternalServerError)
+	var statusErr slack.StatusCodeError
+	if err := slack.PostWebhookCustomHTTP(server.WebhookURL(), server.Client(), msg); !errors.As(err, &statusErr) ||
+		statusErr.Code != http.StatusInternalServerError {
+		t.Errorf("expected a 500 status code error, got: %v", err)
+	}
+
+	server.Delay(1, time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := slack.PostWebhookCustomHTTPContext(ctx, server.WebhookURL(), server.Client(), msg); !errors.Is(err,
+		context.DeadlineExceeded) {
+		t.Errorf("expected a timeout, got: %v", err)
+	}
+	for deadline := time.Now().Add(time.Second); server.Rejected() < 3 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := slack.PostWebhookCustomHTTP(server.WebhookURL(), server.Client(), msg); err != nil {
+		t.Fatalf("failed to post webhook: %s", err.Error())
+	}
+	messages := server.WaitForMessages(t, 1, time.Second)
+	if len(messages) != 1 || server.Rejected() != 3 {
+		t.Fatalf("expected 1 accepted and 3 rejected messages, got %d and %d", len(messages), server.Rejected())
+	}
+	slacktest.AssertBlockTypes(t, messages[0], slack.MBTSection)
+	slacktest.AssertContainsText(t, messages[0], "hello *world*")
+}
+
+func TestWebAPI(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+	client := slack.New("xoxb-test", slack.OptionAPIURL(server.APIURL()), slack.OptionHTTPClient(server.Client()))
+
+	channel, ts, err := client.PostMessage("C123", slack.MsgOptionText("parent", false))
+	if err != nil {
+		t.Fatalf("failed to post message: %s", err.Error())
+	}
+	if channel != "C123" || ts == "" {
+		t.Errorf("unexpected channel or timestamp: %q, %q", channel, ts)
+	}
+	if _, _, err := client.PostMessage("C123", slack.MsgOptionText("reply", false), slack.MsgOptionTS(ts)); err != nil {
+		t.Fatalf("failed to post reply: %s", err.Error())
+	}
+	if _, _, _, err := client.UpdateMessage("C123", ts, slack.MsgOptionText("updated", false)); err != nil {
+		t.Fatalf("failed to update message: %s", err.Error())
+	}
+	if _, err := client.UploadFile(slack.FileUploadParameters{
+		Channels: []string{"C123"},
+		Filename: "output.txt",
+		Reader:   strings.NewReader("file contents"),
+	}); err != nil {
+		t.Fatalf("failed to upload file: %s", err.Error())
+	}
+
+	messages := server.Messages()
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(messages))
+	}
+	if messages[1].ThreadTimestamp != ts {
+		t.Errorf("expected reply in thread %q, got %q", ts, messages[1].ThreadTimestamp)
+	}
+	if messages[2].Endpoint != slacktest.EndpointUpdateMessage || messages[2].Timestamp != ts {
+		t.Errorf("expected an update to %q, got %+v", ts, messages[2])
+	}
+	slacktest.AssertContainsText(t, messages[2], "updated")
+	if messages[3].Endpoint != slacktest.EndpointFileUpload || string(messages[3].FileContent) != "file contents" {
+		t.Errorf("unexpected file upload: %+v", messages[3])
+	}
+}
@@ -0,0 +1,111 @@
+package slacktest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing/slogtest"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+// RecordingFormatter wraps a SlackMessageFormatter and records the structure of every record it formats.
+//
+// Each record is saved as a map in the form expected by testing/slogtest: the time, level and message are stored
+// under the slog.TimeKey, slog.LevelKey and slog.MessageKey keys and groups are stored as nested maps.
+type RecordingFormatter struct {
+	// unexported variables
+	formatter slogxslack.SlackMessageFormatter
+	mu        sync.Mutex
+	records   []map[string]any
+}
+
+// NewRecordingFormatter creates a new formatter which records every record before passing it to the given formatter.
+//
+// If the formatter is nil, DefaultSlackMessageFormatter is used.
+func NewRecordingFormatter(f slogxslack.SlackMessageFormatter) *RecordingFormatter {
+	if f == nil {
+		f = slogxslack.DefaultSlackMessageFormatter()
+	}
+	return &RecordingFormatter{
+		formatter: f,
+		records:   []map[string]any{},
+	}
+}
+
+// FormatRecord records the record and then formats it using the wrapped formatter.
+func (f *RecordingFormatter) FormatRecord(ctx context.Context, timestamp time.Time, level slogx.Level, pc uintptr,
+	msg string, attrs []slog.Attr) (*slack.WebhookMessage, error) {
+
+	record := attrsToMap(attrs)
+	if !timestamp.IsZero() {
+		record[slog.TimeKey] = timestamp
+	}
+	record[slog.LevelKey] = level.Level()
+	record[slog.MessageKey] = msg
+
+	f.mu.Lock()
+	f.records = append(f.records, record)
+	f.mu.Unlock()
+	return f.formatter.FormatRecord(ctx, timestamp, level, pc, msg, attrs)
+}
+
+// Records returns every record formatted so far, in order.
+func (f *RecordingFormatter) Records() []map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]any{}, f.records...)
+}
+
+// TestHandler runs the testing/slogtest conformance suite against a handler which posts to a fake Slack server.
+//
+// The newHandler function should create the handler to test, posting messages to the given webhook URL and using the
+// given formatter to format them. If the handler has a Flush(context.Context) error function, it is called before
+// the results are checked so that asynchronous handlers can be tested as well.
+//
+// In addition to the conformance checks, every record is verified to have been delivered to the fake server.
+func TestHandler(newHandler func(webhookURL string, f slogxslack.SlackMessageFormatter) (slog.Handler, error)) error {
+	server := NewServer()
+	defer server.Close()
+
+	f := NewRecordingFormatter(nil)
+	h, err := newHandler(server.WebhookURL(), f)
+	if err != nil {
+		return fmt.Errorf("failed to create handler: %w", err)
+	}
+
+	var deliveryErr error
+	results := func() []map[string]any {
+		if flusher, ok := h.(interface{ Flush(context.Context) error }); ok {
+			if err := flusher.Flush(context.Background()); err != nil {
+				deliveryErr = fmt.Errorf("failed to flush handler: %w", err)
+			}
+		}
+		records := f.Records()
+		if delivered := len(server.Messages()); deliveryErr == nil && delivered != len(records) {
+			deliveryErr = fmt.Errorf("expected %d message(s) to be delivered, got %d", len(records), delivered)
+		}
+		return records
+	}
+	if err := slogtest.TestHandler(h, results); err != nil {
+		return err
+	}
+	return deliveryErr
+}
+
+// attrsToMap converts the attributes into a map, converting groups into nested maps.
+func attrsToMap(attrs []slog.Attr) map[string]any {
+	m := map[string]any{}
+	for _, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			m[a.Key] = attrsToMap(a.Value.Group())
+		} else {
+			m[a.Key] = a.Value.Any()
+		}
+	}
+	return m
+}
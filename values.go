@@ -0,0 +1,487 @@
+package slogxslack
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultValueMaxDepth is the default maximum depth of nested values rendered as JSON.
+	DefaultValueMaxDepth = 5
+
+	// DefaultValueMaxSize is the default maximum number of characters of a value rendered as JSON.
+	DefaultValueMaxSize = 2000
+
+	// maxValueText is the maximum number of characters of the text displaying a value rendered as JSON, including the
+	// code block around it and the attribute's key, as Slack does not allow the text of a block to be any longer.
+	maxValueText = 3000
+
+	// truncatedValueSuffix is added to the end of values rendered as JSON which were truncated.
+	truncatedValueSuffix = "\n… (truncated)"
+)
+
+// FormatValueFn is a function which renders an attribute value of a specific Go type as mrkdwn text.
+//
+// The returned text is displayed after the attribute's key as-is, so it should escape any characters Slack would
+// interpret as markup (see EscapeMrkdwn()).
+type FormatValueFn func(ctx context.Context, level slog.Leveler, key string, value any) (string, error)
+
+// jsonField is a single field of a jsonObject.
+type jsonField struct {
+	key   string
+	value any
+}
+
+// jsonObject is a JSON object whose fields are marshaled in order.
+type jsonObject []jsonField
+
+// MarshalJSON marshals the fields of the object in order.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// valueRenderer renders composite attribute values as JSON.
+type valueRenderer struct {
+	maxDepth int
+	maxSize  int
+	reserved int
+	seen     map[uintptr]bool
+}
+
+// formatAnyValue renders the value of a slog.KindAny attribute as mrkdwn text.
+//
+// A formatter registered for the value's type in the ValueFormatters option is used first. Otherwise,
+// encoding.TextMarshaler, error and fmt.Stringer values are displayed inline, while json.Marshaler values and
+// composite values (maps, slices, arrays and structs) are pretty-printed as JSON in a code block.
+//
+// The reserved number of characters are kept free of Slack's limit on the length of the text when truncating values
+// rendered as JSON, for the key and indentation the caller displays along with the value.
+func (f slackMessageFormatter) formatAnyValue(ctx context.Context, level slog.Leveler, key string, value any,
+	reserved int) (string, error) {
+
+	if fn, ok := f.options.ValueFormatters[reflect.TypeOf(value)]; ok && fn != nil {
+		return fn(ctx, level, key, value)
+	}
+	if tm, ok := value.(encoding.TextMarshaler); ok {
+		output, err := tm.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return inlineCode(string(output)), nil
+	}
+	if _, ok := value.(json.Marshaler); !ok {
+		switch v := value.(type) {
+		case error:
+			return inlineCode(v.Error()), nil
+		case fmt.Stringer:
+			return inlineCode(v.String()), nil
+		}
+		if !isComposite(reflect.ValueOf(value)) {
+			return inlineCode(fmt.Sprintf("%+v", value)), nil
+		}
+	}
+
+	r := valueRenderer{
+		maxDepth: f.options.ValueMaxDepth,
+		maxSize:  f.options.ValueMaxSize,
+		reserved: reserved,
+		seen:     map[uintptr]bool{},
+	}
+	if r.maxDepth <= 0 {
+		r.maxDepth = DefaultValueMaxDepth
+	}
+	if r.maxSize <= 0 {
+		r.maxSize = DefaultValueMaxSize
+	}
+	return r.render(value)
+}
+
+// render renders the value as indented JSON in a code block, truncating it if it is too large.
+//
+// The size is measured after escaping, so that neither the maximum size nor Slack's limit on the length of the text
+// is exceeded once any characters are replaced by HTML entities. An entity is never split by the truncation.
+func (r *valueRenderer) render(value any) (string, error) {
+	data, err := json.MarshalIndent(r.tree(reflect.ValueOf(value), 0), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	const codeBlock = len("```\n") + len("\n```")
+	text := jsonCodeEscaper.Replace(string(data))
+	maxSize := r.maxSize
+	limit := maxValueText - r.reserved - codeBlock - utf8.RuneCountInString(truncatedValueSuffix)
+	if maxSize > limit {
+		maxSize = max(limit, 0)
+	}
+	if utf8.RuneCountInString(text) > maxSize {
+		text = string([]rune(text)[:maxSize])
+		if i := strings.LastIndexByte(text, '&'); i != -1 && !strings.Contains(text[i:], ";") {
+			text = text[:i]
+		}
+		text += truncatedValueSuffix
+	}
+	return "```\n" + text + "\n```", nil
+}
+
+// inlineCode displays the text as inline code.
+//
+// The characters Slack interprets even within code are escaped and backticks, which would end the code early, are
+// replaced by single quotes.
+func inlineCode(text string) string {
+	return "`" + jsonCodeEscaper.Replace(strings.ReplaceAll(text, "`", "'")) + "`"
+}
+
+// jsonCodeEscaper escapes the characters Slack interprets even within code blocks.
+var jsonCodeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// tree converts the value into a tree of values which can be marshaled as JSON.
+//
+// Values nested deeper than the maximum depth and values which refer back to one of their parents are replaced by a
+// placeholder string.
+func (r *valueRenderer) tree(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+
+	// values which know how to represent themselves
+	if v.CanInterface() {
+		switch i := v.Interface().(type) {
+		case json.Marshaler:
+			data, err := i.MarshalJSON()
+			if err != nil || !json.Valid(data) {
+				return fmt.Sprintf("[invalid JSON: %v]", err)
+			}
+			return json.RawMessage(data)
+		case encoding.TextMarshaler:
+			data, err := i.MarshalText()
+			if err != nil {
+				return fmt.Sprintf("[invalid text: %s]", err.Error())
+			}
+			return string(data)
+		case error:
+			return i.Error()
+		case fmt.Stringer:
+			return i.String()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.Kind() == reflect.Pointer {
+			if r.seen[v.Pointer()] {
+				return "[cycle]"
+			}
+			r.seen[v.Pointer()] = true
+			defer delete(r.seen, v.Pointer())
+		}
+		return r.tree(v.Elem(), depth)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if depth >= r.maxDepth {
+			return "[max depth]"
+		}
+		if r.seen[v.Pointer()] {
+			return "[cycle]"
+		}
+		r.seen[v.Pointer()] = true
+		defer delete(r.seen, v.Pointer())
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = r.tree(iter.Value(), depth+1)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 && utf8.Valid(v.Bytes()) {
+			return string(v.Bytes())
+		}
+		if depth >= r.maxDepth {
+			return "[max depth]"
+		}
+		s := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s = append(s, r.tree(v.Index(i), depth+1))
+		}
+		return s
+	case reflect.Struct:
+		if depth >= r.maxDepth {
+			return "[max depth]"
+		}
+		o := jsonObject{}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			o = append(o, jsonField{key: name, value: r.tree(v.Field(i), depth+1)})
+		}
+		return o
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return v.Type().String()
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// isComposite determines whether or not the value, or the value it points to, is a map, slice, array or struct.
+func isComposite(v reflect.Value) bool {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// ValueFormat holds the settings used to format the values of attributes whose keys match a pattern.
+type ValueFormat struct {
+	// ByteSizes formats integer and float values as byte sizes using binary units (eg: 1.5 MiB).
+	ByteSizes bool
+
+	// DateToken is the format of the Slack date token used to display time values (eg: "{date_short_pretty} at
+	// {time}").
+	//
+	// Slack displays date tokens in each reader's own timezone, using the value formatted with TimeLayout and
+	// TimeLocation as the fallback text. If empty, time values are displayed using TimeLayout.
+	DateToken string
+
+	// DurationPrecision is the unit duration values, and times displayed relative to the record, are rounded to.
+	//
+	// If zero, durations are not rounded and relative times are rounded to the nearest second.
+	DurationPrecision time.Duration
+
+	// FloatPrecision is the number of digits displayed after the decimal point of float values.
+	//
+	// If zero, the shortest representation which converts back to the same value is used (eg: 3.14 rather than
+	// 3.140000). If negative, floats are rounded to whole numbers.
+	FloatPrecision int
+
+	// KeyPattern is the regular expression matched against the full key of the attribute, including any groups (eg:
+	// GROUP.ATTRIBUTE).
+	//
+	// If empty, the format applies to every attribute.
+	KeyPattern string
+
+	// RelativeTime displays time values relative to the time of the record (eg: "5m0s ago" or "in 1h0m0s").
+	RelativeTime bool
+
+	// ThousandsSeparator is inserted between each group of thousands of integer and float values (eg: 1,234,567).
+	//
+	// If empty, no separator is inserted.
+	ThousandsSeparator string
+
+	// TimeLayout is the layout used to display time values.
+	//
+	// If empty, time.RFC3339 is used.
+	TimeLayout string
+
+	// TimeLocation is the location time values are converted to before they are displayed.
+	//
+	// If nil, time.UTC is used.
+	TimeLocation *time.Location
+}
+
+// compiledValueFormat is a value format whose key pattern has been compiled.
+type compiledValueFormat struct {
+	format  ValueFormat
+	pattern *regexp.Regexp
+}
+
+// byteSizeUnits are the binary units used to display byte sizes.
+var byteSizeUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// valueFormat returns the first value format whose key pattern matches the key.
+//
+// If no format matches, an empty format is returned.
+func (f slackMessageFormatter) valueFormat(key string) ValueFormat {
+	for _, vf := range f.valueFormats {
+		if vf.pattern == nil || vf.pattern.MatchString(key) {
+			return vf.format
+		}
+	}
+	return ValueFormat{}
+}
+
+// formatScalarValue formats an int, uint, float, duration or time value using the format.
+//
+// The returned text is wrapped in backticks unless it is a Slack date token, which Slack does not display within
+// code spans. The record time is used to display times relative to the record.
+func (vf ValueFormat) formatScalarValue(value slog.Value, recordTime time.Time) string {
+	switch value.Kind() {
+	case slog.KindInt64:
+		if vf.ByteSizes {
+			return "`" + vf.formatByteSize(float64(value.Int64())) + "`"
+		}
+		return "`" + vf.separateThousands(strconv.FormatInt(value.Int64(), 10)) + "`"
+	case slog.KindUint64:
+		if vf.ByteSizes {
+			return "`" + vf.formatByteSize(float64(value.Uint64())) + "`"
+		}
+		return "`" + vf.separateThousands(strconv.FormatUint(value.Uint64(), 10)) + "`"
+	case slog.KindFloat64:
+		if vf.ByteSizes {
+			return "`" + vf.formatByteSize(value.Float64()) + "`"
+		}
+		return "`" + vf.formatFloat(value.Float64()) + "`"
+	case slog.KindDuration:
+		d := value.Duration()
+		if vf.DurationPrecision > 0 {
+			d = d.Round(vf.DurationPrecision)
+		}
+		return "`" + d.String() + "`"
+	case slog.KindTime:
+		return vf.formatTime(value.Time(), recordTime)
+	}
+	return "`" + value.String() + "`"
+}
+
+// formatByteSize formats the number of bytes using the largest binary unit which keeps the number at least 1.
+func (vf ValueFormat) formatByteSize(n float64) string {
+	unit := 0
+	for math.Abs(n) >= 1024 && unit < len(byteSizeUnits)-1 {
+		n /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return vf.separateThousands(strconv.FormatFloat(n, 'f', -1, 64)) + " " + byteSizeUnits[unit]
+	}
+	precision := vf.FloatPrecision
+	if precision == 0 {
+		precision = 1
+	}
+	text := strconv.FormatFloat(n, 'f', max(precision, 0), 64)
+	if vf.FloatPrecision == 0 {
+		text = strings.TrimSuffix(text, ".0")
+	}
+	return vf.separateThousands(text) + " " + byteSizeUnits[unit]
+}
+
+// formatFloat formats the float using the precision of the format.
+func (vf ValueFormat) formatFloat(n float64) string {
+	var text string
+	switch {
+	case vf.FloatPrecision > 0:
+		text = strconv.FormatFloat(n, 'f', vf.FloatPrecision, 64)
+	case vf.FloatPrecision < 0:
+		text = strconv.FormatFloat(n, 'f', 0, 64)
+	case n != 0 && (math.Abs(n) < 1e-6 || math.Abs(n) >= 1e21):
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	default:
+		text = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return vf.separateThousands(text)
+}
+
+// formatTime formats the time using the layout, location and date token of the format.
+func (vf ValueFormat) formatTime(t, recordTime time.Time) string {
+	if vf.RelativeTime {
+		if recordTime.IsZero() {
+			recordTime = time.Now()
+		}
+		precision := vf.DurationPrecision
+		if precision <= 0 {
+			precision = time.Second
+		}
+		d := recordTime.Sub(t).Round(precision)
+		if d < 0 {
+			return "`in " + (-d).String() + "`"
+		}
+		return "`" + d.String() + " ago`"
+	}
+
+	location := vf.TimeLocation
+	if location == nil {
+		location = time.UTC
+	}
+	layout := vf.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	text := t.In(location).Format(layout)
+	if vf.DateToken != "" {
+		return slackDateToken(t, vf.DateToken, text)
+	}
+	return "`" + text + "`"
+}
+
+// separateThousands inserts the thousands separator of the format into the integer part of the formatted number.
+func (vf ValueFormat) separateThousands(number string) string {
+	if vf.ThousandsSeparator == "" {
+		return number
+	}
+	sign := ""
+	if strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+") {
+		sign, number = number[:1], number[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(number, ".")
+	var b strings.Builder
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(vf.ThousandsSeparator)
+		}
+		b.WriteRune(c)
+	}
+	if hasFraction {
+		return sign + b.String() + "." + fraction
+	}
+	return sign + b.String()
+}
+
+// slackDateToken returns a Slack date token, which Slack displays in each reader's own timezone, for the time.
+//
+// Readers whose Slack client cannot display the token see the fallback text instead.
+func slackDateToken(t time.Time, format, fallback string) string {
+	return fmt.Sprintf("<!date^%d^%s|%s>", t.Unix(), format, strings.NewReplacer("|", "/", ">", "").Replace(fallback))
+}
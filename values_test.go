@@ -0,0 +1,239 @@
+package slogxslack_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"go.innotegrity.dev/slogx"
+	slogxslack "go.innotegrity.dev/slogx-slack"
+)
+
+type testNode struct {
+	Name   string    `json:"name"`
+	Next   *testNode `json:"next,omitempty"`
+	Secret string    `json:"-"`
+	Tags   []string  `json:"tags"`
+}
+
+type testStringer struct {
+	ID int
+}
+
+func (s testStringer) String() string {
+	return fmt.Sprintf("stringer-%d", s.ID)
+}
+
+type testTextMarshaler struct{}
+
+func (testTextMarshaler) MarshalText() ([]byte, error) {
+	return []byte("<@U123> & `code`"), nil
+}
+
+type testMarshaler struct{}
+
+func (testMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"custom":true}`), nil
+}
+
+type testPoint struct {
+	X, Y int
+}
+
+func TestValueRendering(t *testing.T) {
+	cyclic := &testNode{Name: "a", Tags: []string{"x"}}
+	cyclic.Next = &testNode{Name: "b", Next: cyclic, Secret: "hidden"}
+	deep := map[string]any{"l1": map[string]any{"l2": map[string]any{"l3": "too deep"}}}
+
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.ValueMaxDepth = 2
+	opts.ValueMaxSize = 200
+	opts.ValueFormatters = map[reflect.Type]slogxslack.FormatValueFn{
+		reflect.TypeOf(testPoint{}): func(ctx context.Context, level slog.Leveler, key string,
+			value any) (string, error) {
+			p := value.(testPoint)
+			return fmt.Sprintf("(%d, %d)", p.X, p.Y), nil
+		},
+	}
+	texts := formatAttrs(t, opts,
+		slog.Any("cyclic", cyclic),
+		slog.Any("deep", deep),
+		slog.Any("list", make([]int, 100)),
+		slog.Any("marshaler", testMarshaler{}),
+		slog.Any("point", testPoint{X: 1, Y: 2}),
+		slog.Any("stringer", testStringer{ID: 7}),
+		slog.Any("text", testTextMarshaler{}),
+		slog.Any("error", fmt.Errorf("failed: <%s>", "`x`")),
+	)
+
+	cyclicText := texts["cyclic"]
+	if !strings.HasPrefix(cyclicText, "*cyclic*:\n```\n{") || !strings.Contains(cyclicText, `"[cycle]"`) {
+		t.Errorf("expected the cycle to be replaced by a placeholder, got %q", cyclicText)
+	}
+	if strings.Contains(cyclicText, "hidden") || strings.Contains(cyclicText, "Secret") {
+		t.Errorf("expected fields tagged with json:\"-\" to be skipped, got %q", cyclicText)
+	}
+	if !strings.Contains(texts["deep"], `"[max depth]"`) || strings.Contains(texts["deep"], "too deep") {
+		t.Errorf("expected values beyond the maximum depth to be replaced, got %q", texts["deep"])
+	}
+	if !strings.Contains(texts["list"], "(truncated)") {
+		t.Errorf("expected the large value to be truncated, got %q", texts["list"])
+	}
+	var decoded map[string]bool
+	marshaled := strings.TrimSuffix(strings.TrimPrefix(texts["marshaler"], "*marshaler*:\n```\n"), "\n```")
+	if err := json.Unmarshal([]byte(marshaled), &decoded); err != nil || !decoded["custom"] {
+		t.Errorf("expected the value's own JSON to be used, got %q", texts["marshaler"])
+	}
+	if texts["point"] != "*point*: (1, 2)" {
+		t.Errorf("expected the registered formatter to be used, got %q", texts["point"])
+	}
+	if texts["stringer"] != "*stringer*: `stringer-7`" {
+		t.Errorf("expected the value's String() to be used, got %q", texts["stringer"])
+	}
+	if texts["text"] != "*text*: `&lt;@U123&gt; &amp; 'code'`" {
+		t.Errorf("expected the value's text to be escaped, got %q", texts["text"])
+	}
+	if texts["error"] != "*error*: `failed: &lt;'x'&gt;`" {
+		t.Errorf("expected the error's text to be escaped, got %q", texts["error"])
+	}
+}
+
+func TestValueRenderingTruncatesEscapedText(t *testing.T) {
+	value := []string{strings.Repeat("<&>", 2000)}
+	for _, size := range []int{100, 10000} {
+		opts := slogxslack.DefaultSlackMessageFormatterOptions()
+		opts.ValueMaxSize = size
+		text := formatAttrs(t, opts, slog.Any("html", value))["html"]
+		code := strings.TrimSuffix(strings.TrimPrefix(text, "*html*:\n"), "\n```")
+		code = strings.TrimPrefix(strings.TrimSuffix(code, "\n… (truncated)"), "```\n")
+		if n := utf8.RuneCountInString(text); n > 3000 {
+			t.Errorf("%d: expected the value to fit within Slack's limit, got %d characters", size, n)
+		}
+		if size < 3000 && utf8.RuneCountInString(code) > size {
+			t.Errorf("%d: expected the escaped value to be truncated to the maximum size, got %d characters", size,
+				utf8.RuneCountInString(code))
+		}
+		i := strings.LastIndex(code, "&")
+		if strings.Contains(code, "<") || (i != -1 && !strings.Contains(code[i:], ";")) ||
+			!strings.HasSuffix(text, "(truncated)\n```") {
+			t.Errorf("%d: expected the value to be escaped and truncated between entities, got %q", size,
+				code[len(code)-20:])
+		}
+	}
+}
+
+func TestValueRenderingFitsGroupedSections(t *testing.T) {
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.AttrRendering = slogxslack.AttrRenderingGrouped
+	opts.ValueMaxSize = 10000
+	message, err := slogxslack.NewSlackMessageFormatter(opts).FormatRecord(context.Background(), time.Time{},
+		slogx.LevelInfo, 0, "values", []slog.Attr{
+			slog.Group("request", slog.Group("body", slog.Group("payload",
+				slog.Any(strings.Repeat("k", 100), []string{strings.Repeat("<&>", 2000)})))),
+		})
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	found := false
+	for _, b := range message.Blocks.BlockSet {
+		block, ok := b.(*slack.SectionBlock)
+		if !ok || block.Text == nil {
+			continue
+		}
+		if strings.Contains(block.Text.Text, "(truncated)") {
+			found = true
+		}
+		if n := utf8.RuneCountInString(block.Text.Text); n > 3000 {
+			t.Errorf("expected the grouped value to fit within Slack's limit, got %d characters", n)
+		}
+	}
+	if !found {
+		t.Error("expected the grouped value to be truncated")
+	}
+}
+
+// formatAttrs formats a record with the given attributes and returns the text of each attribute by key.
+func formatAttrs(t *testing.T, opts slogxslack.SlackMessageFormatterOptions, attrs ...slog.Attr) map[string]string {
+	t.Helper()
+	message, err := slogxslack.NewSlackMessageFormatter(opts).FormatRecord(context.Background(), time.Time{},
+		slogx.LevelInfo, 0, "values", attrs)
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	texts := map[string]string{}
+	for _, b := range message.Blocks.BlockSet {
+		block, ok := b.(*slack.ContextBlock)
+		if !ok {
+			continue
+		}
+		for _, e := range block.ContextElements.Elements {
+			if text, ok := e.(slack.TextBlockObject); ok && strings.HasPrefix(text.Text, "*") {
+				key, _, _ := strings.Cut(strings.TrimPrefix(text.Text, "*"), "*")
+				texts[key] = text.Text
+			}
+		}
+	}
+	return texts
+}
+
+func TestValueFormats(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database not available: %s", err.Error())
+	}
+	recordTime := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	opts := slogxslack.DefaultSlackMessageFormatterOptions()
+	opts.ValueFormats = []slogxslack.ValueFormat{
+		{KeyPattern: `(^|\.)bytes$`, ByteSizes: true},
+		{KeyPattern: `^deadline$`, DateToken: "{date_short_pretty} at {time}", TimeLayout: time.Kitchen},
+		{KeyPattern: `^started$`, RelativeTime: true, DurationPrecision: time.Minute},
+		{KeyPattern: `^local$`, TimeLayout: "2006-01-02 15:04 MST", TimeLocation: newYork},
+		{KeyPattern: `^ratio$`, FloatPrecision: 2},
+		{ThousandsSeparator: ",", DurationPrecision: time.Second},
+	}
+	message, err := slogxslack.NewSlackMessageFormatter(opts).FormatRecord(context.Background(), recordTime,
+		slogx.LevelInfo, 0, "values", []slog.Attr{
+			slog.Int64("disk.bytes", 1536*1024),
+			slog.Time("deadline", recordTime),
+			slog.Time("started", recordTime.Add(-90*time.Minute)),
+			slog.Time("local", recordTime),
+			slog.Float64("ratio", 0.12345),
+			slog.Int("count", -1234567),
+			slog.Float64("total", 1234.5),
+			slog.Duration("elapsed", 1500*time.Millisecond),
+		})
+	if err != nil {
+		t.Fatalf("failed to format record: %s", err.Error())
+	}
+	texts := map[string]string{}
+	for _, b := range message.Blocks.BlockSet {
+		if block, ok := b.(*slack.ContextBlock); ok {
+			if text, ok := block.ContextElements.Elements[0].(slack.TextBlockObject); ok {
+				key, value, _ := strings.Cut(text.Text, ": ")
+				texts[strings.Trim(key, "*")] = value
+			}
+		}
+	}
+	expected := map[string]string{
+		"disk.bytes": "`1.5 MiB`",
+		"deadline":   fmt.Sprintf("<!date^%d^{date_short_pretty} at {time}|3:04PM>", recordTime.Unix()),
+		"started":    "`1h30m0s ago`",
+		"local":      "`2024-01-02 10:04 EST`",
+		"ratio":      "`0.12`",
+		"count":      "`-1,234,567`",
+		"total":      "`1,234.5`",
+		"elapsed":    "`2s`",
+	}
+	for key, value := range expected {
+		if texts[key] != value {
+			t.Errorf("expected %s to be displayed as %s, got %s", key, value, texts[key])
+		}
+	}
+}